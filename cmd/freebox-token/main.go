@@ -0,0 +1,224 @@
+// Command freebox-token registers an application on a Freebox and prints the
+// app_token to use with the Terraform provider.
+//
+// It must run on the Freebox LAN: the request has to be approved on the box
+// front panel. Usage:
+//
+//	freebox-token [-base-url URL] [-ca-cert FILE] [-tls-server-name NAME]
+//	              [-app-id ID] [-app-name NAME] [-app-version V]
+//	              [-device-name NAME] [-format text|env|tfvars|json] [-out FILE]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "freebox-token:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	baseURL       string
+	caCert        string
+	tlsServerName string
+	app           api.AppDescription
+	format        string
+	out           string
+	pollInterval  time.Duration
+	timeout       time.Duration
+}
+
+func parseFlags(args []string, stderr io.Writer) (*options, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "Terraform"
+	}
+
+	o := &options{}
+	fs := flag.NewFlagSet("freebox-token", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&o.baseURL, "base-url", envOr("FREEBOX_BASE_URL", "http://mafreebox.freebox.fr"), "Freebox URL; the API version is discovered unless the URL ends in /api/vN")
+	fs.StringVar(&o.caCert, "ca-cert", "", "PEM file of an extra CA to trust for an HTTPS -base-url, besides the system and bundled Freebox roots")
+	fs.StringVar(&o.tlsServerName, "tls-server-name", "", "name to verify the HTTPS certificate against, e.g. when -base-url is an IP address")
+	fs.StringVar(&o.app.AppID, "app-id", envOr("FREEBOX_APP_ID", "fr.freebox.terraform"), "application id, must match the provider app_id")
+	fs.StringVar(&o.app.AppName, "app-name", "Terraform", "application name shown on the Freebox")
+	fs.StringVar(&o.app.AppVersion, "app-version", "1.0.0", "application version")
+	fs.StringVar(&o.app.DeviceName, "device-name", hostname, "name of the device requesting access")
+	fs.StringVar(&o.format, "format", "text", "output format: text, env, tfvars or json")
+	fs.StringVar(&o.out, "out", "", "write the token to this file (mode 0600) instead of stdout")
+	fs.DurationVar(&o.pollInterval, "poll-interval", 2*time.Second, "delay between authorization status checks")
+	fs.DurationVar(&o.timeout, "timeout", 5*time.Minute, "how long to wait for approval on the Freebox")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	switch o.format {
+	case "text", "env", "tfvars", "json":
+	default:
+		return nil, fmt.Errorf("unknown -format %q", o.format)
+	}
+	return o, nil
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	o, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	hc, err := httpClient(o)
+	if err != nil {
+		return err
+	}
+	baseURL, err := resolveBaseURL(ctx, hc, o.baseURL)
+	if err != nil {
+		return err
+	}
+
+	c := api.NewClient(api.Config{BaseURL: baseURL, HTTPClient: hc})
+	auth, err := c.RequestAuthorization(ctx, o.app)
+	if err != nil {
+		return fmt.Errorf("request authorization: %w", err)
+	}
+	fmt.Fprintf(stderr, "Authorization requested for %s (track_id=%d). Approve it on the Freebox front panel.\n", o.app.AppID, auth.TrackID)
+
+	last := ""
+	err = c.WaitForAuthorization(ctx, auth.TrackID, o.pollInterval, func(status string) {
+		if status != last {
+			fmt.Fprintf(stderr, "Status: %s\n", status)
+			last = status
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Open a session with the new token to learn what it was granted.
+	sess := api.NewClient(api.Config{BaseURL: baseURL, AppID: o.app.AppID, AppToken: auth.AppToken, HTTPClient: hc})
+	if err := sess.Login(ctx); err != nil {
+		return fmt.Errorf("open session with the new token: %w", err)
+	}
+	printPermissions(stderr, sess.Permissions())
+
+	if o.out == "" {
+		return writeToken(stdout, o.format, o.app.AppID, baseURL, auth.AppToken)
+	}
+	f, err := os.OpenFile(o.out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := writeToken(f, o.format, o.app.AppID, baseURL, auth.AppToken); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "App token written to %s\n", o.out)
+	return nil
+}
+
+// httpClient trusts the same CAs as the provider: the system roots, the
+// bundled Freebox roots and -ca-cert.
+func httpClient(o *options) (*http.Client, error) {
+	caPEM := ""
+	if o.caCert != "" {
+		b, err := os.ReadFile(o.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("read -ca-cert: %w", err)
+		}
+		caPEM = string(b)
+	}
+	tlsCfg, err := api.TLSConfig(caPEM, o.tlsServerName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -ca-cert %s: %w", o.caCert, err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	return &http.Client{Timeout: 15 * time.Second, Transport: transport}, nil
+}
+
+// resolveBaseURL discovers the API version unless raw already names one.
+func resolveBaseURL(ctx context.Context, hc *http.Client, raw string) (string, error) {
+	root, pinned, err := api.SplitBaseURL(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid -base-url: %w", err)
+	}
+	if pinned != 0 {
+		return strings.TrimSuffix(raw, "/"), nil
+	}
+	v, err := api.DiscoverAPIVersion(ctx, hc, root)
+	if err != nil {
+		return "", fmt.Errorf("discover API version: %w", err)
+	}
+	return v.BaseURL(root), nil
+}
+
+func printPermissions(w io.Writer, perms map[string]bool) {
+	names := make([]string, 0, len(perms))
+	for name := range perms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Granted permissions:")
+	for _, name := range names {
+		mark := "no"
+		if perms[name] {
+			mark = "yes"
+		}
+		fmt.Fprintf(w, "  %-12s %s\n", name, mark)
+	}
+	fmt.Fprintln(w, "Change them in Freebox OS: Paramètres de la Freebox > Gestion des accès > Applications.")
+}
+
+func writeToken(w io.Writer, format, appID, baseURL, token string) error {
+	var err error
+	switch format {
+	case "env":
+		_, err = fmt.Fprintf(w, "export FREEBOX_APP_ID=%q\nexport FREEBOX_APP_TOKEN=%q\n", appID, token)
+	case "tfvars":
+		_, err = fmt.Fprintf(w, "freebox_app_token = %q\n", token)
+	case "json":
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"app_id":    appID,
+			"app_token": token,
+			"base_url":  baseURL,
+		})
+	default:
+		_, err = fmt.Fprintf(w, "APP ID:    %s\nAPP TOKEN: %s\nBASE URL:  %s\n", appID, token, baseURL)
+	}
+	return err
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+)
+
+// runAgainst runs the command against srv with fast polling, answering the
+// first authorization request with status unless it is empty.
+func runAgainst(t *testing.T, srv *fbxtest.Server, status string, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	args = append([]string{"-base-url", srv.URL, "-app-id", "fr.freebox.test", "-device-name", "test", "-poll-interval", "10ms"}, args...)
+
+	done := make(chan struct{})
+	if status != "" {
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-time.After(5 * time.Millisecond):
+					srv.SetAuthorizationStatus(1, status)
+				}
+			}
+		}()
+	}
+	var out, errOut bytes.Buffer
+	err = run(context.Background(), args, &out, &errOut)
+	close(done)
+	return out.String(), errOut.String(), err
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		args    []string
+		wantErr string
+	}{
+		{name: "granted", status: api.AuthStatusGranted},
+		{name: "denied", status: api.AuthStatusDenied, wantErr: "authorization denied"},
+		{name: "timeout on the box", status: api.AuthStatusTimeout, wantErr: "authorization timeout"},
+		{name: "never approved", args: []string{"-timeout", "100ms"}, wantErr: "deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fbxtest.NewServer()
+			defer srv.Close()
+
+			stdout, stderr, err := runAgainst(t, srv, tt.status, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if stdout != "" {
+					t.Errorf("stdout = %q, want nothing", stdout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run: %v\n%s", err, stderr)
+			}
+			if !strings.Contains(stdout, "APP ID:    fr.freebox.test\nAPP TOKEN: ") {
+				t.Errorf("stdout = %q", stdout)
+			}
+			if !strings.Contains(stderr, "Granted permissions:") {
+				t.Errorf("stderr = %q, want the granted permissions", stderr)
+			}
+		})
+	}
+}
+
+func TestRunOut(t *testing.T) {
+	srv := fbxtest.NewServer()
+	defer srv.Close()
+	srv.AutoGrant(true)
+	out := filepath.Join(t.TempDir(), "freebox.env")
+
+	stdout, stderr, err := runAgainst(t, srv, "", "-format", "env", "-out", out)
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("stdout = %q, want the token in the file only", stdout)
+	}
+
+	fi, err := os.Stat(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode = %o, want 600", mode)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); !strings.HasPrefix(got, "export FREEBOX_APP_ID=\"fr.freebox.test\"\nexport FREEBOX_APP_TOKEN=\"") {
+		t.Errorf("file = %q", got)
+	}
+}
+
+func TestRunTLS(t *testing.T) {
+	srv := fbxtest.NewServer()
+	defer srv.Close()
+	srv.AutoGrant(true)
+	// The same fake, over HTTPS with a certificate for 127.0.0.1 and
+	// example.com signed by a CA only known through -ca-cert.
+	tlsSrv := httptest.NewUnstartedServer(srv.Config.Handler)
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0) // the rejected handshakes
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsSrv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "trusted", args: []string{"-ca-cert", ca}},
+		{name: "server name", args: []string{"-ca-cert", ca, "-tls-server-name", "example.com"}},
+		{name: "unknown CA", wantErr: "certificate signed by unknown authority"},
+		{name: "wrong server name", args: []string{"-ca-cert", ca, "-tls-server-name", "mafreebox.freebox.fr"}, wantErr: "not mafreebox.freebox.fr"},
+		{name: "not PEM", args: []string{"-ca-cert", notPEM}, wantErr: "invalid -ca-cert " + notPEM + ": no PEM certificate found"},
+		{name: "missing file", args: []string{"-ca-cert", filepath.Join(dir, "missing.pem")}, wantErr: "read -ca-cert"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-base-url", tlsSrv.URL, "-format", "json"}, tt.args...)
+			stdout, stderr, err := runAgainst(t, srv, "", args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run: %v\n%s", err, stderr)
+			}
+			if !strings.Contains(stdout, `"base_url":"`+tlsSrv.URL+"/api/v") {
+				t.Errorf("stdout = %q, want the HTTPS base URL", stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// AppDescription identifies the application asking for an app token.
+type AppDescription struct {
+	AppID      string `json:"app_id"`
+	AppName    string `json:"app_name"`
+	AppVersion string `json:"app_version"`
+	DeviceName string `json:"device_name"`
+}
+
+// Authorization is the answer to an app token request. The token is only
+// usable once the request has been granted on the Freebox front panel.
+type Authorization struct {
+	AppToken string `json:"app_token"`
+	TrackID  int    `json:"track_id"`
+}
+
+// Authorization statuses reported by /login/authorize/{track_id}.
+const (
+	AuthStatusUnknown = "unknown"
+	AuthStatusPending = "pending"
+	AuthStatusTimeout = "timeout"
+	AuthStatusGranted = "granted"
+	AuthStatusDenied  = "denied"
+)
+
+// RequestAuthorization asks the Freebox for a new app token. It must be called
+// from the local network.
+func (c *Client) RequestAuthorization(ctx context.Context, app AppDescription) (*Authorization, error) {
+	out, err := write[Authorization](ctx, c, http.MethodPost, "/login/authorize/", app)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AuthorizationStatus returns the status of the request tracked by trackID.
+func (c *Client) AuthorizationStatus(ctx context.Context, trackID int) (string, error) {
+	out, err := get[struct {
+		Status string `json:"status"`
+	}](ctx, c, fmt.Sprintf("/login/authorize/%d", trackID))
+	return out.Status, err
+}
+
+// WaitForAuthorization polls the request tracked by trackID every interval
+// until it leaves the pending state. It returns nil once granted.
+func (c *Client) WaitForAuthorization(ctx context.Context, trackID int, interval time.Duration, onPoll func(status string)) error {
+	for {
+		status, err := c.AuthorizationStatus(ctx, trackID)
+		if err != nil {
+			return err
+		}
+		if onPoll != nil {
+			onPoll(status)
+		}
+		switch status {
+		case AuthStatusGranted:
+			return nil
+		case AuthStatusPending:
+		default:
+			return fmt.Errorf("authorization %s", status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
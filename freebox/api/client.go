@@ -0,0 +1,312 @@
+// Package api is a typed client for the subset of the Freebox OS HTTP API used
+// by the Terraform provider. It has no dependency on the plugin framework so
+// it can be exercised on its own.
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults applied by NewClient when the matching Config field is zero.
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay         = 10 * time.Second
+)
+
+// Config holds what is needed to build a Client.
+type Config struct {
+	BaseURL    string // e.g. http://mafreebox.freebox.fr/api/v8
+	AppID      string
+	AppToken   string
+	HTTPClient *http.Client // defaults to http.DefaultClient
+	// Version is the discovery document of the box, when it was fetched.
+	Version *APIVersion
+	// MaxRetries is how many times a failed request is retried (see
+	// retryable). Zero disables retries.
+	MaxRetries int
+	// RetryBaseDelay is the first backoff delay, doubled on each retry.
+	RetryBaseDelay time.Duration
+	// MaxParallelRequests caps in-flight requests to the box. Zero means no limit.
+	MaxParallelRequests int
+}
+
+// Client talks to a single Freebox. It is safe for concurrent use.
+type Client struct {
+	baseURL  string
+	appID    string
+	appToken string
+	http     *http.Client
+	version  *APIVersion
+
+	maxRetries int
+	retryDelay time.Duration
+	// slots is a semaphore limiting concurrent requests; nil means unlimited.
+	slots chan struct{}
+
+	// mu guards sessionToken and permissions; loginMu serialises session renewals so that
+	// concurrent callers hitting an expired session only log in once.
+	mu           sync.RWMutex
+	loginMu      sync.Mutex
+	sessionToken string
+	permissions  map[string]bool
+}
+
+// sessionErrorCodes are the envelope error codes returned by the Freebox once
+// the session token has expired or been revoked.
+var sessionErrorCodes = map[string]bool{
+	"auth_required":   true,
+	"invalid_session": true,
+}
+
+// envelope is the wrapper the Freebox puts around every API response.
+type envelope[T any] struct {
+	Success   bool   `json:"success"`
+	Result    T      `json:"result"`
+	Msg       string `json:"msg"`
+	ErrorCode string `json:"error_code"`
+}
+
+func NewClient(cfg Config) *Client {
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		appID:      cfg.AppID,
+		appToken:   cfg.AppToken,
+		http:       hc,
+		version:    cfg.Version,
+		maxRetries: cfg.MaxRetries,
+		retryDelay: cfg.RetryBaseDelay,
+	}
+	if c.retryDelay <= 0 {
+		c.retryDelay = DefaultRetryBaseDelay
+	}
+	if cfg.MaxParallelRequests > 0 {
+		c.slots = make(chan struct{}, cfg.MaxParallelRequests)
+	}
+	return c
+}
+
+func (c *Client) BaseURL() string { return c.baseURL }
+
+func (c *Client) AppID() string { return c.appID }
+
+// Version returns the discovery document the client was built with, or nil.
+func (c *Client) Version() *APIVersion { return c.version }
+
+// SupportsAPI reports whether the box serves API major version major. It
+// assumes yes when the version was not discovered.
+func (c *Client) SupportsAPI(major int) bool {
+	return c.version == nil || c.version.Major() >= major
+}
+
+func (c *Client) token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sessionToken
+}
+
+// Login runs the challenge/HMAC flow and opens a new session.
+func (c *Client) Login(ctx context.Context) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	return c.openSession(ctx)
+}
+
+func (c *Client) openSession(ctx context.Context) error {
+	// Step 1: get challenge
+	var challenge envelope[struct {
+		Challenge string `json:"challenge"`
+	}]
+	if err := c.send(ctx, http.MethodGet, "/login/", nil, "", &challenge); err != nil {
+		return fmt.Errorf("get challenge: %w", err)
+	}
+	if challenge.Result.Challenge == "" {
+		return fmt.Errorf("empty challenge from Freebox")
+	}
+
+	// Step 2: compute password = HMAC-SHA1(app_token, challenge)
+	mac := hmac.New(sha1.New, []byte(c.appToken))
+	mac.Write([]byte(challenge.Result.Challenge))
+	password := hex.EncodeToString(mac.Sum(nil))
+
+	// Step 3: open session
+	body, _ := json.Marshal(map[string]string{
+		"app_id":   c.appID,
+		"password": password,
+	})
+	var sess envelope[struct {
+		SessionToken string          `json:"session_token"`
+		Permissions  map[string]bool `json:"permissions"`
+	}]
+	if err := c.send(ctx, http.MethodPost, "/login/session/", body, "", &sess); err != nil {
+		return fmt.Errorf("open session: %w", err)
+	}
+	if sess.Result.SessionToken == "" {
+		return fmt.Errorf("no session token returned (msg=%s, error_code=%s)", sess.Msg, sess.ErrorCode)
+	}
+
+	c.mu.Lock()
+	c.sessionToken = sess.Result.SessionToken
+	c.permissions = sess.Result.Permissions
+	c.mu.Unlock()
+	return nil
+}
+
+// Permissions returns the permissions granted to the app in the current
+// session, keyed by Freebox permission name ("settings", "explorer", ...).
+func (c *Client) Permissions() map[string]bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]bool, len(c.permissions))
+	for k, v := range c.permissions {
+		out[k] = v
+	}
+	return out
+}
+
+// HasPermission reports whether perm was granted. known is false when the box
+// did not send a permission list, in which case granted should not be trusted.
+func (c *Client) HasPermission(perm string) (granted, known bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.permissions) == 0 {
+		return false, false
+	}
+	return c.permissions[perm], true
+}
+
+// renewSession opens a new session unless another caller already replaced the
+// stale token while we were waiting for the lock.
+func (c *Client) renewSession(ctx context.Context, stale string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	if c.token() != stale {
+		return nil
+	}
+	return c.openSession(ctx)
+}
+
+// call sends in (if any) as JSON, and decodes the envelope result into out (if
+// any). Transient failures are retried with exponential backoff, up to
+// maxRetries times.
+func (c *Client) call(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = b
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.callSession(ctx, method, path, body, out)
+		if err == nil || ctx.Err() != nil || attempt >= c.maxRetries || !retryable(method, err) {
+			return err
+		}
+		if err := sleep(ctx, c.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// callSession sends the request once and, when the Freebox reports the
+// session as expired, opens a new session and replays it once.
+func (c *Client) callSession(ctx context.Context, method, path string, body []byte, out any) error {
+	for attempt := 0; ; attempt++ {
+		token := c.token()
+		err := c.send(ctx, method, path, body, token, out)
+		if attempt > 0 || !isSessionError(err) {
+			return err
+		}
+		if err := c.renewSession(ctx, token); err != nil {
+			return fmt.Errorf("renew session: %w", err)
+		}
+	}
+}
+
+// send performs a single HTTP exchange. out must be a pointer to an envelope
+// or nil; failures reported by the Freebox are returned as *APIError.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, token string, out any) error {
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+			defer func() { <-c.slots }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, rd)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Fbx-App-Auth", token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	ok := res.StatusCode >= 200 && res.StatusCode < 300
+	if ok && len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+
+	var env envelope[json.RawMessage]
+	if err := json.Unmarshal(b, &env); err != nil {
+		if !ok {
+			return &APIError{HTTPStatus: res.StatusCode, Msg: string(b), Domain: domainOf(path)}
+		}
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if !ok || !env.Success {
+		return &APIError{HTTPStatus: res.StatusCode, ErrorCode: env.ErrorCode, Msg: env.Msg, Domain: domainOf(path)}
+	}
+	if out != nil {
+		if err := json.Unmarshal(b, out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// get and friends are thin wrappers that unwrap the envelope result.
+
+func get[T any](ctx context.Context, c *Client, path string) (T, error) {
+	var env envelope[T]
+	err := c.call(ctx, http.MethodGet, path, nil, &env)
+	return env.Result, err
+}
+
+func write[T any](ctx context.Context, c *Client, method, path string, in any) (T, error) {
+	var env envelope[T]
+	err := c.call(ctx, method, path, in, &env)
+	return env.Result, err
+}
@@ -0,0 +1,150 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+)
+
+func newTestClient(t *testing.T, retries int) (*api.Client, *fbxtest.Server) {
+	t.Helper()
+	srv := fbxtest.NewServer()
+	t.Cleanup(srv.Close)
+	c := api.NewClient(api.Config{
+		BaseURL:        srv.BaseURL(),
+		AppID:          srv.AppID,
+		AppToken:       srv.AppToken,
+		MaxRetries:     retries,
+		RetryBaseDelay: time.Millisecond,
+	})
+	return c, srv
+}
+
+func TestClientRenewsExpiredSession(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newTestClient(t, 0)
+
+	if _, err := c.GetDhcpConfig(ctx); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	srv.ExpireSessions()
+	if _, err := c.GetDhcpConfig(ctx); err != nil {
+		t.Fatalf("call after expiry: %v", err)
+	}
+	if got := srv.Logins(); got != 2 {
+		t.Errorf("logins = %d, want 2 (initial + renewal)", got)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		status  int
+		code    string
+		fails   int
+		retries int
+		create  bool // POST instead of GET
+		wantErr string
+	}{
+		{name: "busy get", status: http.StatusServiceUnavailable, code: "busy", fails: 2, retries: 3},
+		{name: "busy post", status: http.StatusServiceUnavailable, code: "busy", fails: 2, retries: 3, create: true},
+		{name: "5xx get", status: http.StatusInternalServerError, code: "internal_error", fails: 1, retries: 3},
+		{name: "5xx post not retried", status: http.StatusInternalServerError, code: "internal_error", fails: 1, retries: 3, create: true, wantErr: "internal_error"},
+		{name: "retries exhausted", status: http.StatusServiceUnavailable, code: "busy", fails: 3, retries: 2, wantErr: "busy"},
+		{name: "retries disabled", status: http.StatusServiceUnavailable, code: "busy", fails: 1, retries: 0, wantErr: "busy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := newTestClient(t, tt.retries)
+			if err := c.Login(ctx); err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			srv.FailNext(tt.fails, tt.status, tt.code)
+
+			var err error
+			if tt.create {
+				_, err = c.CreateStaticLease(ctx, api.StaticLease{Mac: "AA:BB:CC:DD:EE:01", IP: "192.168.1.10"})
+			} else {
+				_, err = c.GetDhcpConfig(ctx)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && api.ErrorCode(err) != tt.wantErr:
+				t.Fatalf("error = %v, want error_code %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientMaxParallelRequests(t *testing.T) {
+	const calls = 20
+	for _, limit := range []int{1, 3, 0} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			srv := fbxtest.NewServer()
+			t.Cleanup(srv.Close)
+
+			// Count the requests the box is answering at once.
+			var mu sync.Mutex
+			inFlight, peak := 0, 0
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				inFlight++
+				peak = max(peak, inFlight)
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				srv.Config.Handler.ServeHTTP(w, r)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}))
+			t.Cleanup(proxy.Close)
+
+			c := api.NewClient(api.Config{
+				BaseURL:             proxy.URL + "/api/v8",
+				AppID:               srv.AppID,
+				AppToken:            srv.AppToken,
+				MaxParallelRequests: limit,
+			})
+			ctx := context.Background()
+			if err := c.Login(ctx); err != nil {
+				t.Fatalf("login: %v", err)
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, calls)
+			for i := 0; i < calls; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := c.GetDhcpConfig(ctx); err != nil {
+						errs <- err
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Fatalf("call: %v", err)
+			}
+
+			switch {
+			case limit > 0 && peak > limit:
+				t.Errorf("%d requests in flight at once, want at most %d", peak, limit)
+			case limit > 0 && peak < limit:
+				t.Errorf("%d requests in flight at once, want the %d allowed used", peak, limit)
+			case limit == 0 && peak <= 3:
+				t.Errorf("%d requests in flight at once without a limit, want more", peak)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DhcpConfig is the DHCP server configuration (/dhcp/config/).
+type DhcpConfig struct {
+	Enabled              bool     `json:"enabled"`
+	StickyAssign         bool     `json:"sticky_assign"`
+	Gateway              string   `json:"gateway"` // read-only
+	Netmask              string   `json:"netmask"` // read-only
+	IPRangeStart         string   `json:"ip_range_start"`
+	IPRangeEnd           string   `json:"ip_range_end"`
+	AlwaysBroadcast      bool     `json:"always_broadcast"`
+	IgnoreOutOfRangeHint bool     `json:"ignore_out_of_range_hint"`
+	DNS                  []string `json:"dns"`
+}
+
+// DhcpConfigUpdate carries the DHCP settings to change; nil fields are left
+// as they are on the box.
+type DhcpConfigUpdate struct {
+	Enabled              *bool     `json:"enabled,omitempty"`
+	StickyAssign         *bool     `json:"sticky_assign,omitempty"`
+	IPRangeStart         *string   `json:"ip_range_start,omitempty"`
+	IPRangeEnd           *string   `json:"ip_range_end,omitempty"`
+	AlwaysBroadcast      *bool     `json:"always_broadcast,omitempty"`
+	IgnoreOutOfRangeHint *bool     `json:"ignore_out_of_range_hint,omitempty"`
+	DNS                  *[]string `json:"dns,omitempty"`
+}
+
+// StaticLease is a DHCP static lease (/dhcp/static_lease/). Its id is the MAC.
+type StaticLease struct {
+	ID       string   `json:"id,omitempty"`
+	Mac      string   `json:"mac"`
+	IP       string   `json:"ip"`
+	Comment  string   `json:"comment,omitempty"`
+	Hostname string   `json:"hostname,omitempty"` // read-only
+	Host     *LanHost `json:"host,omitempty"`     // read-only, the LAN browser host of the MAC
+}
+
+// DynamicLease is an address currently handed out by the DHCP server
+// (/dhcp/dynamic_lease/). Times are Unix timestamps; LeaseRemaining is in
+// seconds.
+type DynamicLease struct {
+	Mac            string   `json:"mac"`
+	IP             string   `json:"ip"`
+	Hostname       string   `json:"hostname,omitempty"`
+	LeaseRemaining int64    `json:"lease_remaining"`
+	AssignTime     int64    `json:"assign_time"`
+	RefreshTime    int64    `json:"refresh_time"`
+	IsStatic       bool     `json:"is_static"`
+	Host           *LanHost `json:"host,omitempty"`
+}
+
+// StaticLeaseUpdate carries the fields to change on an existing lease; nil
+// fields are left untouched.
+type StaticLeaseUpdate struct {
+	IP      *string `json:"ip,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+func (c *Client) GetDhcpConfig(ctx context.Context) (*DhcpConfig, error) {
+	cfg, err := get[DhcpConfig](ctx, c, "/dhcp/config/")
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Client) UpdateDhcpConfig(ctx context.Context, cfg DhcpConfigUpdate) (*DhcpConfig, error) {
+	out, err := write[DhcpConfig](ctx, c, http.MethodPut, "/dhcp/config/", cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListStaticLeases(ctx context.Context) ([]StaticLease, error) {
+	return get[[]StaticLease](ctx, c, "/dhcp/static_lease/")
+}
+
+func (c *Client) ListDynamicLeases(ctx context.Context) ([]DynamicLease, error) {
+	return get[[]DynamicLease](ctx, c, "/dhcp/dynamic_lease/")
+}
+
+func (c *Client) GetStaticLease(ctx context.Context, id string) (*StaticLease, error) {
+	l, err := get[StaticLease](ctx, c, staticLeasePath(id))
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (c *Client) CreateStaticLease(ctx context.Context, l StaticLease) (*StaticLease, error) {
+	out, err := write[StaticLease](ctx, c, http.MethodPost, "/dhcp/static_lease/", l)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateStaticLease(ctx context.Context, id string, u StaticLeaseUpdate) (*StaticLease, error) {
+	out, err := write[StaticLease](ctx, c, http.MethodPut, staticLeasePath(id), u)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteStaticLease(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, staticLeasePath(id), nil, nil)
+}
+
+// staticLeasePath returns the path of lease id, which is its MAC address in
+// the form the box uses.
+func staticLeasePath(id string) string {
+	if mac := NormalizeMAC(id); mac != "" {
+		id = mac
+	}
+	return "/dhcp/static_lease/" + url.PathEscape(id)
+}
+
+// NormalizeMAC returns mac upper-cased and colon-separated, as the Freebox
+// stores it, or "" when mac is not a 48-bit MAC address. Dashes and dots
+// (aa-bb-cc-dd-ee-ff, aabb.ccdd.eeff) are accepted.
+func NormalizeMAC(mac string) string {
+	hw, err := net.ParseMAC(strings.ReplaceAll(mac, "-", ":"))
+	if err != nil || len(hw) != 6 {
+		return ""
+	}
+	return strings.ToUpper(hw.String())
+}
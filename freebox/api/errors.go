@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the Freebox answers with a non-2xx status or with
+// success=false in the response envelope.
+type APIError struct {
+	HTTPStatus int
+	ErrorCode  string
+	Msg        string
+	// Domain is the API area the failing request belonged to ("dhcp", "fw",
+	// ...). It selects which catalogue entry describes ErrorCode.
+	Domain string
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("status %d: %s", e.HTTPStatus, e.Msg)
+	}
+	if human := e.Description(); human != "" {
+		return fmt.Sprintf("status %d: %s (error_code=%s: %s)", e.HTTPStatus, e.Msg, e.ErrorCode, human)
+	}
+	return fmt.Sprintf("status %d: %s (error_code=%s)", e.HTTPStatus, e.Msg, e.ErrorCode)
+}
+
+// Description returns the human readable meaning of ErrorCode, or "" when the
+// code is not in the catalogue.
+func (e *APIError) Description() string {
+	return DescribeError(e.Domain, e.ErrorCode)
+}
+
+// DescribeError looks code up in the catalogue of domain, falling back to the
+// codes shared by every domain.
+func DescribeError(domain, code string) string {
+	if human, ok := errorCatalogue[domain][code]; ok {
+		return human
+	}
+	return commonErrors[code]
+}
+
+// commonErrors are returned by any endpoint: authentication failures and the
+// errno-style codes used throughout Freebox OS.
+var commonErrors = map[string]string{
+	"auth_required":           "invalid session token, or no session token sent",
+	"invalid_session":         "the session has expired or been closed",
+	"invalid_token":           "the app token is invalid or has been revoked",
+	"pending_token":           "the app token has not been validated on the Freebox yet",
+	"insufficient_rights":     "the app permissions do not allow accessing this API",
+	"denied_from_external_ip": "app tokens can only be requested from the local network",
+	"invalid_request":         "the request is invalid",
+	"ratelimited":             "too many authentication errors from this IP",
+	"new_apps_denied":         "new application token requests have been disabled",
+	"apps_denied":             "API access from apps has been disabled",
+	"internal_error":          "internal Freebox error",
+	"invalid_api_version":     "this API version is not supported by the Freebox",
+	"inval":                   "invalid argument",
+	"exist":                   "already exists",
+	"nodev":                   "no such device",
+	"noent":                   "no such entry",
+	"netdown":                 "network is down",
+	"busy":                    "device or resource busy",
+	"nospc":                   "no space left",
+	"perm":                    "operation not permitted",
+	"nomem":                   "out of memory",
+	"notsup":                  "operation not supported",
+	"timeout":                 "operation timed out",
+}
+
+// errorCatalogue holds the per-domain meaning of error codes, overriding
+// commonErrors where a domain gives a code a more precise sense.
+var errorCatalogue = map[string]map[string]string{
+	"dhcp": {
+		"inval":              "invalid DHCP setting",
+		"inval_netmask":      "invalid netmask",
+		"inval_ip_range":     "invalid IP range",
+		"inval_ip_range_net": "IP range & netmask mismatch",
+		"inval_gw_net":       "gateway & netmask mismatch",
+		"exist":              "a static lease already exists for this MAC or IP",
+		"noent":              "no such static lease",
+		"nospc":              "the maximum number of static leases has been reached",
+		"busy":               "the DHCP server is busy",
+		"notsup":             "the DHCP server cannot be configured in the current network mode",
+	},
+	"fw": {
+		"inval":  "invalid forwarding rule",
+		"exist":  "a forwarding rule already uses this WAN port",
+		"noent":  "no such forwarding rule",
+		"nospc":  "the maximum number of forwarding rules has been reached",
+		"notsup": "this forwarding rule is not supported in the current network mode",
+	},
+	"lan_config": {
+		"inval":   "invalid LAN setting",
+		"busy":    "the LAN is being reconfigured",
+		"netdown": "the LAN interface is down",
+		"notsup":  "this LAN setting is not supported in the current network mode",
+	},
+	"lan_browser": {
+		"inval":   "invalid LAN host setting",
+		"nodev":   "invalid interface",
+		"noent":   "invalid host id",
+		"netdown": "interface is down",
+		"busy":    "interface is busy",
+	},
+	"lan": {
+		"inval":   "invalid LAN request",
+		"nodev":   "invalid interface",
+		"netdown": "interface is down",
+		"busy":    "interface is busy",
+	},
+	"wifi": {
+		"inval":   "invalid Wi-Fi setting",
+		"nodev":   "no such Wi-Fi access point",
+		"noent":   "no such Wi-Fi entry",
+		"busy":    "the Wi-Fi access point is busy (e.g. channel scan in progress)",
+		"netdown": "Wi-Fi is disabled",
+	},
+	"vpn": {
+		"inval":  "invalid VPN setting",
+		"exist":  "a VPN user or configuration with this name already exists",
+		"noent":  "no such VPN user or configuration",
+		"nospc":  "the maximum number of VPN users has been reached",
+		"busy":   "the VPN server is busy",
+		"notsup": "this VPN type is not supported",
+	},
+}
+
+// domainOf derives the catalogue domain from an API path such as
+// "/dhcp/static_lease/". The LAN configuration and the LAN browser answer
+// with the same codes in different senses, so they get a domain each.
+func domainOf(path string) string {
+	p := strings.TrimPrefix(path, "/")
+	switch {
+	case strings.HasPrefix(p, "lan/config/"):
+		return "lan_config"
+	case strings.HasPrefix(p, "lan/browser/"):
+		return "lan_browser"
+	}
+	if i := strings.IndexByte(p, '/'); i >= 0 {
+		p = p[:i]
+	}
+	switch {
+	case strings.HasPrefix(p, "vpn"):
+		return "vpn"
+	case strings.HasPrefix(p, "wifi"):
+		return "wifi"
+	}
+	return p
+}
+
+// ErrorCode returns the Freebox error_code carried by err, or "".
+func ErrorCode(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode
+	}
+	return ""
+}
+
+// IsNotFound reports whether err means the requested object does not exist.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.HTTPStatus == http.StatusNotFound || apiErr.ErrorCode == "noent"
+}
+
+func isSessionError(err error) bool {
+	return sessionErrorCodes[ErrorCode(err)]
+}
@@ -0,0 +1,50 @@
+package api
+
+import "testing"
+
+func TestDomainOf(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"/dhcp/static_lease/AA:BB:CC:DD:EE:01", "dhcp"},
+		{"/fw/redir/1", "fw"},
+		{"/lan/config/", "lan_config"},
+		{"/lan/browser/pub/ether-aa:bb:cc:dd:ee:01", "lan_browser"},
+		{"/lan/wol/pub/", "lan"},
+		{"/wifi/ap/0", "wifi"},
+		{"/vpn_client/config/", "vpn"},
+	}
+	for _, tt := range tests {
+		if got := domainOf(tt.path); got != tt.want {
+			t.Errorf("domainOf(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct{ domain, code, want string }{
+		{"lan_config", "inval", "invalid LAN setting"},
+		{"lan_browser", "noent", "invalid host id"},
+		{"dhcp", "inval_gw_net", "gateway & netmask mismatch"},
+		{"fw", "exist", "a forwarding rule already uses this WAN port"},
+		// Not in the domain table: the common meaning.
+		{"lan_config", "insufficient_rights", "the app permissions do not allow accessing this API"},
+		{"", "noent", "no such entry"},
+		{"dhcp", "no_such_code", ""},
+	}
+	for _, tt := range tests {
+		if got := DescribeError(tt.domain, tt.code); got != tt.want {
+			t.Errorf("DescribeError(%q, %q) = %q, want %q", tt.domain, tt.code, got, tt.want)
+		}
+	}
+}
+
+// Every domain entry must refine a code, not misspell one.
+func TestErrorCatalogueCodes(t *testing.T) {
+	domainCodes := map[string]bool{"inval_netmask": true, "inval_ip_range": true, "inval_ip_range_net": true, "inval_gw_net": true}
+	for domain, codes := range errorCatalogue {
+		for code := range codes {
+			if _, ok := commonErrors[code]; !ok && !domainCodes[code] {
+				t.Errorf("%s: %q is neither a common code nor a known domain code", domain, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// PortForward is a port forwarding rule (/fw/redir/).
+type PortForward struct {
+	ID           int             `json:"id,omitempty"` // omitempty so create doesn't send 0
+	Enabled      bool            `json:"enabled"`
+	IpProto      string          `json:"ip_proto"`       // "tcp" | "udp"
+	WanPortStart int             `json:"wan_port_start"` // required
+	WanPortEnd   int             `json:"wan_port_end"`   // required
+	LanIP        string          `json:"lan_ip"`         // required
+	LanPort      int             `json:"lan_port"`       // required
+	SrcIP        string          `json:"src_ip"`         // default "0.0.0.0"
+	Comment      string          `json:"comment,omitempty"`
+	Hostname     string          `json:"hostname,omitempty"` // read-only
+	Host         json.RawMessage `json:"host,omitempty"`     // read-only
+}
+
+func (c *Client) ListPortForwards(ctx context.Context) ([]PortForward, error) {
+	return get[[]PortForward](ctx, c, "/fw/redir/")
+}
+
+func (c *Client) GetPortForward(ctx context.Context, id int) (*PortForward, error) {
+	pf, err := get[PortForward](ctx, c, portForwardPath(id))
+	if err != nil {
+		return nil, err
+	}
+	return &pf, nil
+}
+
+func (c *Client) CreatePortForward(ctx context.Context, pf PortForward) (*PortForward, error) {
+	pf.ID = 0
+	out, err := write[PortForward](ctx, c, http.MethodPost, "/fw/redir/", pf)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PortForwardUpdate is the body of a rule update. The box applies it as a
+// partial update, keeping the current value of any field left out, so every
+// field is always sent: an empty Comment clears the comment.
+type PortForwardUpdate struct {
+	ID           int    `json:"id"`
+	Enabled      bool   `json:"enabled"`
+	IpProto      string `json:"ip_proto"`
+	WanPortStart int    `json:"wan_port_start"`
+	WanPortEnd   int    `json:"wan_port_end"`
+	LanIP        string `json:"lan_ip"`
+	LanPort      int    `json:"lan_port"`
+	SrcIP        string `json:"src_ip"`
+	Comment      string `json:"comment"`
+}
+
+// UpdatePortForward sets every writable field of rule id. The id is also sent
+// in the body since the API checks that it matches the URL.
+func (c *Client) UpdatePortForward(ctx context.Context, id int, u PortForwardUpdate) (*PortForward, error) {
+	u.ID = id
+	out, err := write[PortForward](ctx, c, http.MethodPut, portForwardPath(id), u)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeletePortForward(ctx context.Context, id int) error {
+	return c.call(ctx, http.MethodDelete, portForwardPath(id), nil, nil)
+}
+
+func portForwardPath(id int) string {
+	return fmt.Sprintf("/fw/redir/%d", id)
+}
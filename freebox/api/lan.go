@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// LAN modes.
+const (
+	LanModeRouter = "router"
+	LanModeBridge = "bridge"
+)
+
+// LanConfig is the LAN configuration (/lan/config/): the box address on the
+// LAN and the names it announces.
+type LanConfig struct {
+	IP          string `json:"ip"`
+	Name        string `json:"name"`
+	NameDNS     string `json:"name_dns"`
+	NameMDNS    string `json:"name_mdns"`
+	NameNetbios string `json:"name_netbios"`
+	Mode        string `json:"mode"`
+}
+
+// LanConfigUpdate carries the LAN settings to change; nil fields are left as
+// they are on the box.
+type LanConfigUpdate struct {
+	IP          *string `json:"ip,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	NameDNS     *string `json:"name_dns,omitempty"`
+	NameMDNS    *string `json:"name_mdns,omitempty"`
+	NameNetbios *string `json:"name_netbios,omitempty"`
+	Mode        *string `json:"mode,omitempty"`
+}
+
+func (c *Client) GetLanConfig(ctx context.Context) (*LanConfig, error) {
+	cfg, err := get[LanConfig](ctx, c, "/lan/config/")
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Client) UpdateLanConfig(ctx context.Context, u LanConfigUpdate) (*LanConfig, error) {
+	out, err := write[LanConfig](ctx, c, http.MethodPut, "/lan/config/", u)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LanMainInterface is the LAN browser interface of the main LAN, where the
+// hosts of DHCP leases live.
+const LanMainInterface = "pub"
+
+// LanInterface is a LAN browser interface (/lan/browser/interfaces/), such as
+// "pub" for the main LAN or "wifiguest" for the guest Wi-Fi.
+type LanInterface struct {
+	Name      string `json:"name"`
+	HostCount int    `json:"host_count"`
+}
+
+// LanHost is a device seen by the LAN browser (/lan/browser/{interface}/).
+type LanHost struct {
+	ID                string                  `json:"id"`
+	PrimaryName       string                  `json:"primary_name"`
+	HostType          string                  `json:"host_type"`
+	PrimaryNameManual bool                    `json:"primary_name_manual"`
+	L2Ident           LanHostL2Ident          `json:"l2ident"`
+	VendorName        string                  `json:"vendor_name"`
+	Persistent        bool                    `json:"persistent"`
+	Reachable         bool                    `json:"reachable"`
+	LastTimeReachable int64                   `json:"last_time_reachable"`
+	Active            bool                    `json:"active"`
+	LastActivity      int64                   `json:"last_activity"`
+	FirstActivity     int64                   `json:"first_activity"`
+	Names             []LanHostName           `json:"names,omitempty"`
+	L3Connectivities  []LanHostL3Connectivity `json:"l3connectivities,omitempty"`
+}
+
+// LanHostL2Ident identifies a host at layer 2, usually by MAC address.
+type LanHostL2Ident struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // "mac_address"
+}
+
+// LanHostName is a name a host announced, with where it was learnt from
+// (dhcp, netbios, mdns, upnp, ...).
+type LanHostName struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// LanHostL3Connectivity is an address a host was seen using.
+type LanHostL3Connectivity struct {
+	Addr              string `json:"addr"`
+	Af                string `json:"af"` // "ipv4" or "ipv6"
+	Active            bool   `json:"active"`
+	Reachable         bool   `json:"reachable"`
+	LastActivity      int64  `json:"last_activity"`
+	LastTimeReachable int64  `json:"last_time_reachable"`
+}
+
+// LanHostTypes are the host_type values Freebox OS accepts; each one is a
+// device icon in the UI.
+var LanHostTypes = []string{
+	"workstation", "laptop", "smartphone", "tablet", "printer", "vg_console",
+	"television", "nas", "ip_camera", "ip_phone", "freebox_player", "freebox_hd",
+	"freebox_crystal", "freebox_mini", "freebox_delta", "freebox_one",
+	"freebox_wifi", "freebox_pop", "networking_device", "multimedia_device",
+	"car", "other",
+}
+
+// LanHostUpdate carries the host settings to change; nil fields are left as
+// they are on the box.
+type LanHostUpdate struct {
+	PrimaryName *string `json:"primary_name,omitempty"`
+	HostType    *string `json:"host_type,omitempty"`
+	Persistent  *bool   `json:"persistent,omitempty"`
+}
+
+func (c *Client) ListLanInterfaces(ctx context.Context) ([]LanInterface, error) {
+	return get[[]LanInterface](ctx, c, "/lan/browser/interfaces/")
+}
+
+// ListLanHosts returns the hosts seen on iface.
+func (c *Client) ListLanHosts(ctx context.Context, iface string) ([]LanHost, error) {
+	return get[[]LanHost](ctx, c, "/lan/browser/"+url.PathEscape(iface)+"/")
+}
+
+func (c *Client) GetLanHost(ctx context.Context, iface, id string) (*LanHost, error) {
+	h, err := get[LanHost](ctx, c, lanHostPath(iface, id))
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (c *Client) UpdateLanHost(ctx context.Context, iface, id string, u LanHostUpdate) (*LanHost, error) {
+	out, err := write[LanHost](ctx, c, http.MethodPut, lanHostPath(iface, id), u)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func lanHostPath(iface, id string) string {
+	return "/lan/browser/" + url.PathEscape(iface) + "/" + url.PathEscape(id)
+}
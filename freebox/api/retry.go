@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// idempotent methods can be replayed after a transport failure without risk
+// of applying a change twice.
+var idempotent = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// retryable reports whether a request that failed with err is worth sending
+// again. "busy" and 429 mean the box did not process the request, so any
+// method is retried; transport errors (including per-request timeouts) and
+// 5xx only for idempotent methods.
+func retryable(method string, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.ErrorCode == "busy", apiErr.HTTPStatus == http.StatusTooManyRequests:
+			return true
+		case apiErr.HTTPStatus >= 500:
+			return idempotent[method]
+		}
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && idempotent[method]
+}
+
+// backoff returns the delay before retry number attempt (0-based): the base
+// delay doubled each time, capped, with up to 20% jitter so concurrent
+// callers do not retry in lockstep.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.retryDelay << attempt
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d - time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
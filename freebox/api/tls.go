@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// rootCAs holds the bundled Freebox root CAs, one PEM file each, as published
+// in the Freebox OS SDK documentation.
+//
+//go:embed certs/*.pem
+var rootCAs embed.FS
+
+// TLSConfig returns the TLS settings used to reach a Freebox over HTTPS. The
+// system roots and the bundled Freebox root CAs are trusted, plus caPEM when
+// set. serverName overrides the name checked against the certificate, which
+// is needed when base_url uses an IP address.
+func TLSConfig(caPEM, serverName string) (*tls.Config, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if err := appendRootCAs(pool); err != nil {
+		return nil, err
+	}
+	if caPEM != "" && !pool.AppendCertsFromPEM([]byte(caPEM)) {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+
+	return &tls.Config{
+		RootCAs:    pool,
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// appendRootCAs adds the bundled Freebox root CAs to pool.
+func appendRootCAs(pool *x509.CertPool) error {
+	names, err := fs.Glob(rootCAs, "certs/*.pem")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		b, err := rootCAs.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return fmt.Errorf("no certificate found in bundled %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTLSConfigBundlesFreeboxRoots(t *testing.T) {
+	cfg, err := TLSConfig("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, _ := fs.Glob(rootCAs, "certs/*.pem")
+	if len(names) == 0 {
+		t.Fatal("no bundled root CA")
+	}
+	for _, name := range names {
+		b, _ := rootCAs.ReadFile(name)
+		block, _ := pem.Decode(b)
+		if block == nil {
+			t.Fatalf("%s: no PEM block", name)
+		}
+		root, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.HasPrefix(root.Subject.CommonName, "Freebox") {
+			t.Errorf("%s: subject %s", name, root.Subject)
+		}
+		if err := root.CheckSignatureFrom(root); err != nil {
+			t.Errorf("%s: not a valid self-signed root: %v", name, err)
+		}
+		if _, err := root.Verify(x509.VerifyOptions{Roots: cfg.RootCAs}); err != nil {
+			t.Errorf("%s: not trusted by TLSConfig: %v", name, err)
+		}
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+	// httptest certificates are valid for 127.0.0.1 and example.com.
+	srvPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	tests := []struct {
+		name       string
+		caPEM      string
+		serverName string
+		wantErr    string
+	}{
+		{name: "trusted ca", caPEM: srvPEM},
+		{name: "server name override", caPEM: srvPEM, serverName: "example.com"},
+		{name: "wrong server name", caPEM: srvPEM, serverName: "mafreebox.freebox.fr", wantErr: "not mafreebox.freebox.fr"},
+		{name: "unknown ca", wantErr: "unknown authority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := TLSConfig(tt.caPEM, tt.serverName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hc := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+			res, err := hc.Get(srv.URL)
+			if err == nil {
+				res.Body.Close()
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := TLSConfig("not a certificate", ""); err == nil {
+		t.Error("invalid ca_cert_pem accepted")
+	}
+}
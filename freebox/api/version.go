@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the discovery document served at /api_version. Unlike the
+// rest of the API it is not wrapped in an envelope.
+type APIVersion struct {
+	UID            string `json:"uid"`
+	DeviceName     string `json:"device_name"`
+	DeviceType     string `json:"device_type"`
+	APIVersion     string `json:"api_version"`  // e.g. "8.0"
+	APIBaseURL     string `json:"api_base_url"` // e.g. "/api/"
+	APIDomain      string `json:"api_domain"`   // <id>.fbxos.fr
+	HTTPSAvailable bool   `json:"https_available"`
+	HTTPSPort      int    `json:"https_port"`
+	BoxModel       string `json:"box_model"`
+	BoxModelName   string `json:"box_model_name"`
+}
+
+// Major returns the major API version, e.g. 8 for "8.0", or 0 if unknown.
+func (v *APIVersion) Major() int {
+	major, _, _ := strings.Cut(v.APIVersion, ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
+
+// BaseURL builds the versioned API base URL under root, e.g.
+// http://mafreebox.freebox.fr/api/v8. root is kept as given: api_domain and
+// https_port only make up RemoteURL.
+func (v *APIVersion) BaseURL(root string) string {
+	prefix := v.APIBaseURL
+	if prefix == "" {
+		prefix = "/api/"
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return strings.TrimSuffix(root, "/") + prefix + "v" + strconv.Itoa(v.Major())
+}
+
+// RemoteURL returns the HTTPS remote-access root of the box,
+// https://<api_domain>:<https_port>, or "" when HTTPS remote access is off.
+func (v *APIVersion) RemoteURL() string {
+	if !v.HTTPSAvailable || v.APIDomain == "" || v.HTTPSPort == 0 {
+		return ""
+	}
+	return "https://" + net.JoinHostPort(v.APIDomain, strconv.Itoa(v.HTTPSPort))
+}
+
+var versionedPath = regexp.MustCompile(`/v(\d+)/?$`)
+
+// SplitBaseURL returns the scheme://host[:port] root of rawURL, and the API
+// major version when the path already names one (".../api/v8").
+func SplitBaseURL(rawURL string) (root string, major int, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", 0, fmt.Errorf("%q is not an absolute URL", rawURL)
+	}
+	root = u.Scheme + "://" + u.Host
+	if m := versionedPath.FindStringSubmatch(u.Path); m != nil {
+		major, _ = strconv.Atoi(m[1])
+	}
+	return root, major, nil
+}
+
+// DiscoverAPIVersion fetches /api_version from the Freebox at root.
+func DiscoverAPIVersion(ctx context.Context, hc *http.Client, root string) (*APIVersion, error) {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(root, "/")+"/api_version", nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, &APIError{HTTPStatus: res.StatusCode, Msg: "api_version discovery failed"}
+	}
+	var v APIVersion
+	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode api_version: %w", err)
+	}
+	if v.Major() == 0 {
+		return nil, fmt.Errorf("unexpected api_version %q", v.APIVersion)
+	}
+	return &v, nil
+}
+
+// DiscoverVersion fetches /api_version from the box the client points at.
+func (c *Client) DiscoverVersion(ctx context.Context) (*APIVersion, error) {
+	root, _, err := SplitBaseURL(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return DiscoverAPIVersion(ctx, c.http, root)
+}
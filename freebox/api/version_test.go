@@ -0,0 +1,49 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+)
+
+func TestAPIVersionURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      api.APIVersion
+		base   string
+		remote string
+	}{
+		{
+			name:   "remote access on",
+			v:      api.APIVersion{APIVersion: "8.0", APIBaseURL: "/api/", APIDomain: "abcd1234.fbxos.fr", HTTPSAvailable: true, HTTPSPort: 41234},
+			base:   "http://mafreebox.freebox.fr/api/v8",
+			remote: "https://abcd1234.fbxos.fr:41234",
+		},
+		{
+			name: "remote access off",
+			v:    api.APIVersion{APIVersion: "6.3", APIBaseURL: "/api/", APIDomain: "abcd1234.fbxos.fr", HTTPSPort: 41234},
+			base: "http://mafreebox.freebox.fr/api/v6",
+		},
+		{
+			name: "no domain",
+			v:    api.APIVersion{APIVersion: "4.0", HTTPSAvailable: true, HTTPSPort: 443},
+			base: "http://mafreebox.freebox.fr/api/v4",
+		},
+		{
+			name: "prefix without trailing slash",
+			v:    api.APIVersion{APIVersion: "8.2", APIBaseURL: "/fbx", APIDomain: "abcd1234.fbxos.fr", HTTPSAvailable: true},
+			base: "http://mafreebox.freebox.fr/fbx/v8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The root is kept as given, whatever remote access offers.
+			if got := tt.v.BaseURL("http://mafreebox.freebox.fr/"); got != tt.base {
+				t.Errorf("BaseURL = %q, want %q", got, tt.base)
+			}
+			if got := tt.v.RemoteURL(); got != tt.remote {
+				t.Errorf("RemoteURL = %q, want %q", got, tt.remote)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package freebox
+
+import (
+	"context"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &apiVersionDataSource{}
+	_ datasource.DataSourceWithConfigure = &apiVersionDataSource{}
+)
+
+func NewAPIVersionDataSource() datasource.DataSource { return &apiVersionDataSource{} }
+
+type apiVersionDataSource struct{ client *api.Client }
+
+type apiVersionDSModel struct {
+	Id             types.String `tfsdk:"id"`
+	BaseURL        types.String `tfsdk:"base_url"`
+	ApiVersion     types.String `tfsdk:"api_version"`
+	ApiMajor       types.Int64  `tfsdk:"api_major"`
+	ApiBaseURL     types.String `tfsdk:"api_base_url"`
+	ApiDomain      types.String `tfsdk:"api_domain"`
+	HttpsAvailable types.Bool   `tfsdk:"https_available"`
+	HttpsPort      types.Int64  `tfsdk:"https_port"`
+	RemoteURL      types.String `tfsdk:"remote_url"`
+	BoxModel       types.String `tfsdk:"box_model"`
+	BoxModelName   types.String `tfsdk:"box_model_name"`
+	DeviceName     types.String `tfsdk:"device_name"`
+	DeviceType     types.String `tfsdk:"device_type"`
+}
+
+func (d *apiVersionDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "freebox_api_version"
+}
+
+func (d *apiVersionDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dschema.Schema{
+		Description: "Read the Freebox API discovery document (/api_version).",
+		Attributes: map[string]dschema.Attribute{
+			"id":              dschema.StringAttribute{Computed: true, Description: "Box unique id."},
+			"base_url":        dschema.StringAttribute{Computed: true, Description: "API base URL the provider uses."},
+			"api_version":     dschema.StringAttribute{Computed: true, Description: "Latest API version served by the box, e.g. 8.0."},
+			"api_major":       dschema.Int64Attribute{Computed: true, Description: "Major part of api_version."},
+			"api_base_url":    dschema.StringAttribute{Computed: true, Description: "API path prefix, e.g. /api/."},
+			"api_domain":      dschema.StringAttribute{Computed: true, Description: "Remote-access domain (<id>.fbxos.fr)."},
+			"https_available": dschema.BoolAttribute{Computed: true, Description: "Whether HTTPS remote access is enabled."},
+			"https_port":      dschema.Int64Attribute{Computed: true, Description: "HTTPS remote-access port."},
+			"remote_url":      dschema.StringAttribute{Computed: true, Description: "HTTPS remote-access URL, https://<api_domain>:<https_port>, to use as base_url from outside the LAN. Null when HTTPS remote access is off."},
+			"box_model":       dschema.StringAttribute{Computed: true},
+			"box_model_name":  dschema.StringAttribute{Computed: true},
+			"device_name":     dschema.StringAttribute{Computed: true},
+			"device_type":     dschema.StringAttribute{Computed: true},
+		},
+	}
+}
+
+func (d *apiVersionDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.client = req.ProviderData.(*api.Client)
+	}
+}
+
+func (d *apiVersionDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	v, err := d.client.DiscoverVersion(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+
+	state := apiVersionDSModel{
+		Id:             types.StringValue(v.UID),
+		BaseURL:        types.StringValue(d.client.BaseURL()),
+		ApiVersion:     types.StringValue(v.APIVersion),
+		ApiMajor:       types.Int64Value(int64(v.Major())),
+		ApiBaseURL:     stringOrNull(v.APIBaseURL),
+		ApiDomain:      stringOrNull(v.APIDomain),
+		HttpsAvailable: types.BoolValue(v.HTTPSAvailable),
+		HttpsPort:      types.Int64Value(int64(v.HTTPSPort)),
+		RemoteURL:      stringOrNull(v.RemoteURL()),
+		BoxModel:       stringOrNull(v.BoxModel),
+		BoxModelName:   stringOrNull(v.BoxModelName),
+		DeviceName:     stringOrNull(v.DeviceName),
+		DeviceType:     stringOrNull(v.DeviceType),
+	}
+	if v.UID == "" {
+		state.Id = types.StringValue("api_version")
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
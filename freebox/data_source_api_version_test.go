@@ -0,0 +1,86 @@
+package freebox
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAPIVersionDataSource(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "data.freebox_api_version.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, `data "freebox_api_version" "test" {}`+"\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", "fbxtest"),
+					resource.TestCheckResourceAttr(addr, "base_url", srv.BaseURL()),
+					resource.TestCheckResourceAttr(addr, "api_version", "8.0"),
+					resource.TestCheckResourceAttr(addr, "api_major", "8"),
+					resource.TestCheckResourceAttr(addr, "api_base_url", "/api/"),
+					resource.TestCheckResourceAttr(addr, "api_domain", "fbxtest.fbxos.fr"),
+					resource.TestCheckResourceAttr(addr, "https_available", "true"),
+					resource.TestCheckResourceAttr(addr, "https_port", "443"),
+					resource.TestCheckResourceAttr(addr, "remote_url", "https://fbxtest.fbxos.fr:443"),
+					resource.TestCheckResourceAttr(addr, "box_model", "fbxgw-r2/full"),
+					resource.TestCheckResourceAttr(addr, "box_model_name", "Freebox v6 (r2)"),
+					resource.TestCheckResourceAttr(addr, "device_name", "Freebox Server"),
+					resource.TestCheckResourceAttr(addr, "device_type", "FreeboxServer1,2"),
+				),
+			},
+		},
+	})
+}
+
+// A box serving an older API than the one the fake defaults to: the provider
+// uses that version, and every resource works with it.
+func TestAccAPIVersionOlder(t *testing.T) {
+	srv := newTestServer(t)
+	srv.SetAPIVersion("4.0")
+	testLanHostAt(srv, testLeaseMAC, "nas", "192.168.1.42")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, `
+data "freebox_api_version" "test" {}
+
+resource "freebox_dhcp_lease" "test" {
+  mac = "AA:BB:CC:DD:EE:01"
+  ip  = "192.168.1.42"
+}
+
+resource "freebox_port_forwarding" "test" {
+  wan_port_start = 8080
+  wan_port_end   = 8080
+  lan_ip         = freebox_dhcp_lease.test.ip
+  lan_port       = 80
+}
+
+resource "freebox_lan_host" "test" {
+  host_id      = "ether-aa:bb:cc:dd:ee:01"
+  primary_name = "nas"
+}
+
+resource "freebox_lan_config" "test" {
+  name_dns = "box"
+}
+
+resource "freebox_dhcp_config" "test" {
+  ip_range_end = "192.168.1.40"
+}
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.freebox_api_version.test", "api_major", "4"),
+					resource.TestCheckResourceAttr("data.freebox_api_version.test", "base_url", srv.URL+"/api/v4"),
+					resource.TestCheckResourceAttr("freebox_port_forwarding.test", "hostname", "nas"),
+					checkLease(srv, "192.168.1.42", ""),
+				),
+			},
+		},
+	})
+}
@@ -2,11 +2,8 @@ package freebox
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -19,7 +16,7 @@ var (
 
 func NewDhcpConfigDataSource() datasource.DataSource { return &dhcpConfigDataSource{} }
 
-type dhcpConfigDataSource struct{ client *Client }
+type dhcpConfigDataSource struct{ client *api.Client }
 
 type dhcpConfigDSModel struct {
 	Id                   types.String   `tfsdk:"id"`
@@ -56,9 +53,10 @@ func (d *dhcpConfigDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 	}
 }
 
-func (d *dhcpConfigDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+func (d *dhcpConfigDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
 	if req.ProviderData != nil {
-		d.client = req.ProviderData.(*Client)
+		d.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(d.client, "freebox_dhcp_config", apiMinDHCP, &resp.Diagnostics)
 	}
 }
 
@@ -67,40 +65,23 @@ func (d *dhcpConfigDataSource) Read(ctx context.Context, _ datasource.ReadReques
 		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
 		return
 	}
-	req, _ := d.client.newRequest(ctx, http.MethodGet, "/dhcp/config/", nil)
-	res, err := d.client.http.Do(req)
+	cfg, err := d.client.GetDhcpConfig(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(res.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", res.StatusCode, string(b)))
-		return
-	}
-
-	var env envCfg[apiDhcpConfig]
-	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
-		return
-	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", env.Msg)
-		return
-	}
 
 	state := dhcpConfigDSModel{
 		Id:                   types.StringValue("dhcp_config"),
-		Enabled:              types.BoolValue(env.Result.Enabled),
-		StickyAssign:         types.BoolValue(env.Result.StickyAssign),
-		Gateway:              stringOrNull(env.Result.Gateway),
-		Netmask:              stringOrNull(env.Result.Netmask),
-		IpRangeStart:         stringOrNull(env.Result.IPRangeStart),
-		IpRangeEnd:           stringOrNull(env.Result.IPRangeEnd),
-		AlwaysBroadcast:      types.BoolValue(env.Result.AlwaysBroadcast),
-		IgnoreOutOfRangeHint: types.BoolValue(env.Result.IgnoreOutOfRangeHint),
-		Dns:                  flattenStringList(env.Result.DNS),
+		Enabled:              types.BoolValue(cfg.Enabled),
+		StickyAssign:         types.BoolValue(cfg.StickyAssign),
+		Gateway:              stringOrNull(cfg.Gateway),
+		Netmask:              stringOrNull(cfg.Netmask),
+		IpRangeStart:         stringOrNull(cfg.IPRangeStart),
+		IpRangeEnd:           stringOrNull(cfg.IPRangeEnd),
+		AlwaysBroadcast:      types.BoolValue(cfg.AlwaysBroadcast),
+		IgnoreOutOfRangeHint: types.BoolValue(cfg.IgnoreOutOfRangeHint),
+		Dns:                  flattenStringList(cfg.DNS),
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
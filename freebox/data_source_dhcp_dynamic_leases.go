@@ -0,0 +1,101 @@
+package freebox
+
+import (
+	"context"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &dhcpDynamicLeasesDataSource{}
+	_ datasource.DataSourceWithConfigure = &dhcpDynamicLeasesDataSource{}
+)
+
+func NewDhcpDynamicLeasesDataSource() datasource.DataSource { return &dhcpDynamicLeasesDataSource{} }
+
+type dhcpDynamicLeasesDataSource struct{ client *api.Client }
+
+type dynamicLeasesDSModel struct {
+	Id     types.String       `tfsdk:"id"`
+	Leases []dynamicLeaseItem `tfsdk:"leases"`
+}
+
+type dynamicLeaseItem struct {
+	Mac            types.String `tfsdk:"mac"`
+	Ip             types.String `tfsdk:"ip"`
+	Hostname       types.String `tfsdk:"hostname"`
+	LeaseRemaining types.Int64  `tfsdk:"lease_remaining"`
+	AssignTime     types.Int64  `tfsdk:"assign_time"`
+	RefreshTime    types.Int64  `tfsdk:"refresh_time"`
+	IsStatic       types.Bool   `tfsdk:"is_static"`
+	Host           *lanHostItem `tfsdk:"host"`
+}
+
+func (d *dhcpDynamicLeasesDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "freebox_dhcp_dynamic_leases"
+}
+
+func (d *dhcpDynamicLeasesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dschema.Schema{
+		Description: "List the addresses currently handed out by the DHCP server (API v8).",
+		Attributes: map[string]dschema.Attribute{
+			"id": dschema.StringAttribute{Computed: true, Description: "Synthetic ID."},
+			"leases": dschema.ListNestedAttribute{
+				Computed:    true,
+				Description: "All DHCP dynamic leases, including those of devices with a static lease.",
+				NestedObject: dschema.NestedAttributeObject{Attributes: map[string]dschema.Attribute{
+					"mac":             dschema.StringAttribute{Computed: true},
+					"ip":              dschema.StringAttribute{Computed: true},
+					"hostname":        dschema.StringAttribute{Computed: true, Description: "Hostname announced by the device."},
+					"lease_remaining": dschema.Int64Attribute{Computed: true, Description: "Seconds before the lease expires."},
+					"assign_time":     dschema.Int64Attribute{Computed: true, Description: "Unix time the address was first assigned."},
+					"refresh_time":    dschema.Int64Attribute{Computed: true, Description: "Unix time the lease was last renewed."},
+					"is_static":       dschema.BoolAttribute{Computed: true, Description: "Whether the device has a static lease."},
+					"host": dschema.SingleNestedAttribute{
+						Computed:    true,
+						Description: "LAN browser host of the MAC address, or null when the box has not seen it.",
+						Attributes:  lanHostAttributes(),
+					},
+				}},
+			},
+		},
+	}
+}
+
+func (d *dhcpDynamicLeasesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(d.client, "freebox_dhcp_dynamic_leases", apiMinDHCP, &resp.Diagnostics)
+	}
+}
+
+func (d *dhcpDynamicLeasesDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	leases, err := d.client.ListDynamicLeases(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+
+	out := dynamicLeasesDSModel{Id: types.StringValue("dhcp_dynamic_leases")}
+	out.Leases = make([]dynamicLeaseItem, 0, len(leases))
+	for _, l := range leases {
+		var host *lanHostItem
+		if l.Host != nil {
+			h := lanHostToItem(api.LanMainInterface, *l.Host)
+			host = &h
+		}
+		out.Leases = append(out.Leases, dynamicLeaseItem{
+			Mac: types.StringValue(l.Mac), Ip: types.StringValue(l.IP), Hostname: stringOrNull(l.Hostname),
+			LeaseRemaining: types.Int64Value(l.LeaseRemaining), AssignTime: types.Int64Value(l.AssignTime), RefreshTime: types.Int64Value(l.RefreshTime),
+			IsStatic: types.BoolValue(l.IsStatic), Host: host,
+		})
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &out)...)
+}
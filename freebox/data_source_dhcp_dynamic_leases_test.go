@@ -0,0 +1,49 @@
+package freebox
+
+import (
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccDhcpDynamicLeasesDataSource(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "nas", HostType: "nas", L2Ident: api.LanHostL2Ident{ID: testLeaseMAC}})
+	srv.PutStaticLease(api.StaticLease{Mac: testLeaseMAC, IP: "192.168.1.42"})
+	srv.PutDynamicLease(api.DynamicLease{Mac: testLeaseMAC, IP: "192.168.1.42", LeaseRemaining: 3600, AssignTime: 1700000000, RefreshTime: 1700001000})
+	srv.PutDynamicLease(api.DynamicLease{Mac: "aa-bb-cc-dd-ee-02", IP: "192.168.1.7", Hostname: "laptop", LeaseRemaining: 600})
+	addr := "data.freebox_dhcp_dynamic_leases.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, `data "freebox_dhcp_dynamic_leases" "test" {}`+"\n"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "leases.#", "2"),
+					resource.TestCheckResourceAttr(addr, "leases.0.mac", testLeaseMAC),
+					resource.TestCheckResourceAttr(addr, "leases.0.ip", "192.168.1.42"),
+					resource.TestCheckResourceAttr(addr, "leases.0.is_static", "true"),
+					resource.TestCheckResourceAttr(addr, "leases.0.lease_remaining", "3600"),
+					resource.TestCheckResourceAttr(addr, "leases.0.assign_time", "1700000000"),
+					resource.TestCheckResourceAttr(addr, "leases.0.refresh_time", "1700001000"),
+					// Named after the LAN browser host.
+					resource.TestCheckResourceAttr(addr, "leases.0.hostname", "nas"),
+					resource.TestCheckResourceAttr(addr, "leases.0.host.primary_name", "nas"),
+
+					resource.TestCheckResourceAttr(addr, "leases.1.mac", "AA:BB:CC:DD:EE:02"),
+					resource.TestCheckResourceAttr(addr, "leases.1.hostname", "laptop"),
+					resource.TestCheckResourceAttr(addr, "leases.1.is_static", "false"),
+					resource.TestCheckNoResourceAttr(addr, "leases.1.host"),
+				),
+			},
+			{
+				// Expired: the next read no longer lists it.
+				PreConfig: func() { srv.DeleteDynamicLease("AA:BB:CC:DD:EE:02") },
+				Config:    testConfig(srv, `data "freebox_dhcp_dynamic_leases" "test" {}`+"\n"),
+				Check:     resource.TestCheckResourceAttr(addr, "leases.#", "1"),
+			},
+		},
+	})
+}
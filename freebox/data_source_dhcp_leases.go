@@ -2,11 +2,8 @@ package freebox
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -19,7 +16,7 @@ var (
 
 func NewDhcpLeasesDataSource() datasource.DataSource { return &dhcpLeasesDataSource{} }
 
-type dhcpLeasesDataSource struct{ client *Client }
+type dhcpLeasesDataSource struct{ client *api.Client }
 
 type leasesDSModel struct {
 	Id     types.String   `tfsdk:"id"`
@@ -32,7 +29,7 @@ type leaseItemOut struct {
 	Ip       types.String `tfsdk:"ip"`
 	Comment  types.String `tfsdk:"comment"`
 	Hostname types.String `tfsdk:"hostname"`
-	Host     types.String `tfsdk:"host"`
+	Host     *lanHostItem `tfsdk:"host"`
 }
 
 func (d *dhcpLeasesDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -53,16 +50,21 @@ func (d *dhcpLeasesDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 					"ip":       dschema.StringAttribute{Computed: true},
 					"comment":  dschema.StringAttribute{Computed: true},
 					"hostname": dschema.StringAttribute{Computed: true},
-					"host":     dschema.StringAttribute{Computed: true},
+					"host": dschema.SingleNestedAttribute{
+						Computed:    true,
+						Description: "LAN browser host of the MAC address, or null when the box has not seen it.",
+						Attributes:  lanHostAttributes(),
+					},
 				}},
 			},
 		},
 	}
 }
 
-func (d *dhcpLeasesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+func (d *dhcpLeasesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
 	if req.ProviderData != nil {
-		d.client = req.ProviderData.(*Client)
+		d.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(d.client, "freebox_dhcp_leases", apiMinDHCP, &resp.Diagnostics)
 	}
 }
 
@@ -71,42 +73,26 @@ func (d *dhcpLeasesDataSource) Read(ctx context.Context, _ datasource.ReadReques
 		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
 		return
 	}
-	req, _ := d.client.newRequest(ctx, http.MethodGet, "/dhcp/static_lease/", nil)
-	res, err := d.client.http.Do(req)
+	leases, err := d.client.ListStaticLeases(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(res.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", res.StatusCode, string(b)))
-		return
-	}
-
-	var env envCfg[[]apiLease]
-	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
-		return
-	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", env.Msg)
-		return
-	}
 
 	out := leasesDSModel{Id: types.StringValue("dhcp_leases")}
-	out.Leases = make([]leaseItemOut, 0, len(env.Result))
-	for _, l := range env.Result {
-		host := ""
-		if len(l.Host) > 0 {
-			host = string(l.Host)
+	out.Leases = make([]leaseItemOut, 0, len(leases))
+	for _, l := range leases {
+		var host *lanHostItem
+		if l.Host != nil {
+			h := lanHostToItem(api.LanMainInterface, *l.Host)
+			host = &h
 		}
-		id := l.Id
+		id := l.ID
 		if id == "" {
 			id = l.Mac
 		}
 		out.Leases = append(out.Leases, leaseItemOut{
-			Id: types.StringValue(id), Mac: types.StringValue(l.Mac), Ip: types.StringValue(l.Ip), Comment: stringOrNull(l.Comment), Hostname: stringOrNull(l.Hostname), Host: stringOrNull(host),
+			Id: types.StringValue(id), Mac: types.StringValue(l.Mac), Ip: types.StringValue(l.IP), Comment: stringOrNull(l.Comment), Hostname: stringOrNull(l.Hostname), Host: host,
 		})
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &out)...)
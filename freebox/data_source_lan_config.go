@@ -0,0 +1,57 @@
+package freebox
+
+import (
+	"context"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+)
+
+var (
+	_ datasource.DataSource              = &lanConfigDataSource{}
+	_ datasource.DataSourceWithConfigure = &lanConfigDataSource{}
+)
+
+func NewLanConfigDataSource() datasource.DataSource { return &lanConfigDataSource{} }
+
+type lanConfigDataSource struct{ client *api.Client }
+
+func (d *lanConfigDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "freebox_lan_config"
+}
+
+func (d *lanConfigDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dschema.Schema{
+		Description: "Read Freebox LAN configuration (API v8).",
+		Attributes: map[string]dschema.Attribute{
+			"id":           dschema.StringAttribute{Computed: true, Description: "Synthetic ID."},
+			"ip":           dschema.StringAttribute{Computed: true, Description: "Freebox IPv4 address on the LAN."},
+			"name":         dschema.StringAttribute{Computed: true, Description: "Freebox name."},
+			"name_dns":     dschema.StringAttribute{Computed: true, Description: "DNS name of the Freebox on the LAN."},
+			"name_mdns":    dschema.StringAttribute{Computed: true, Description: "mDNS (Bonjour) name of the Freebox."},
+			"name_netbios": dschema.StringAttribute{Computed: true, Description: "NetBIOS name of the Freebox."},
+			"mode":         dschema.StringAttribute{Computed: true, Description: `Network mode: "router" or "bridge".`},
+		},
+	}
+}
+
+func (d *lanConfigDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(d.client, "freebox_lan_config", apiMinLanConfig, &resp.Diagnostics)
+	}
+}
+
+func (d *lanConfigDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	cfg, err := d.client.GetLanConfig(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, lanCfgToModel(*cfg))...)
+}
@@ -0,0 +1,191 @@
+package freebox
+
+import (
+	"context"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ datasource.DataSource              = &lanHostsDataSource{}
+	_ datasource.DataSourceWithConfigure = &lanHostsDataSource{}
+)
+
+func NewLanHostsDataSource() datasource.DataSource { return &lanHostsDataSource{} }
+
+type lanHostsDataSource struct{ client *api.Client }
+
+type lanHostsDSModel struct {
+	Id        types.String  `tfsdk:"id"`
+	Interface types.String  `tfsdk:"interface"`
+	Active    types.Bool    `tfsdk:"active"`
+	HostType  types.String  `tfsdk:"host_type"`
+	Hosts     []lanHostItem `tfsdk:"hosts"`
+}
+
+type lanHostItem struct {
+	Id               types.String    `tfsdk:"id"`
+	Interface        types.String    `tfsdk:"interface"`
+	PrimaryName      types.String    `tfsdk:"primary_name"`
+	HostType         types.String    `tfsdk:"host_type"`
+	VendorName       types.String    `tfsdk:"vendor_name"`
+	L2Ident          lanHostL2Item   `tfsdk:"l2ident"`
+	L3Connectivities []lanHostL3Item `tfsdk:"l3connectivities"`
+	Active           types.Bool      `tfsdk:"active"`
+	Reachable        types.Bool      `tfsdk:"reachable"`
+	LastActivity     types.Int64     `tfsdk:"last_activity"`
+	FirstActivity    types.Int64     `tfsdk:"first_activity"`
+}
+
+type lanHostL2Item struct {
+	Id   types.String `tfsdk:"id"`
+	Type types.String `tfsdk:"type"`
+}
+
+type lanHostL3Item struct {
+	Addr         types.String `tfsdk:"addr"`
+	Af           types.String `tfsdk:"af"`
+	Active       types.Bool   `tfsdk:"active"`
+	Reachable    types.Bool   `tfsdk:"reachable"`
+	LastActivity types.Int64  `tfsdk:"last_activity"`
+}
+
+// lanHostsErrors points unknown interface errors at the interface filter.
+var lanHostsErrors = errorScope{codes: map[string]string{"nodev": "interface"}}
+
+func (d *lanHostsDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "freebox_lan_hosts"
+}
+
+func (d *lanHostsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dschema.Schema{
+		Description: "List the devices seen by the Freebox LAN browser (API v8).",
+		Attributes: map[string]dschema.Attribute{
+			"id": dschema.StringAttribute{Computed: true, Description: "Synthetic ID."},
+
+			// Filters.
+			"interface": dschema.StringAttribute{Optional: true, Description: `LAN browser interface to list, such as "pub" (main LAN) or "wifiguest". Defaults to every interface.`},
+			"active":    dschema.BoolAttribute{Optional: true, Description: "When set, only list hosts whose active state matches."},
+			"host_type": dschema.StringAttribute{Optional: true, Description: `When set, only list hosts of this type, such as "workstation" or "smartphone".`},
+
+			"hosts": dschema.ListNestedAttribute{
+				Computed:     true,
+				Description:  "Hosts matching the filters.",
+				NestedObject: dschema.NestedAttributeObject{Attributes: lanHostAttributes()},
+			},
+		},
+	}
+}
+
+// lanHostAttributes is the schema of a LAN browser host, shared by the data
+// sources that return hosts.
+func lanHostAttributes() map[string]dschema.Attribute {
+	return map[string]dschema.Attribute{
+		"id":           dschema.StringAttribute{Computed: true, Description: `Host ID, such as "ether-aa:bb:cc:dd:ee:ff".`},
+		"interface":    dschema.StringAttribute{Computed: true, Description: "Interface the host was seen on."},
+		"primary_name": dschema.StringAttribute{Computed: true, Description: "Host name shown in Freebox OS."},
+		"host_type":    dschema.StringAttribute{Computed: true, Description: "Device type (icon) of the host."},
+		"vendor_name":  dschema.StringAttribute{Computed: true, Description: "Vendor, from the MAC address prefix."},
+		"l2ident": dschema.SingleNestedAttribute{
+			Computed:    true,
+			Description: "Layer 2 identity of the host.",
+			Attributes: map[string]dschema.Attribute{
+				"id":   dschema.StringAttribute{Computed: true, Description: "MAC address."},
+				"type": dschema.StringAttribute{Computed: true, Description: `Identity type, usually "mac_address".`},
+			},
+		},
+		"l3connectivities": dschema.ListNestedAttribute{
+			Computed:    true,
+			Description: "IP addresses the host was seen using.",
+			NestedObject: dschema.NestedAttributeObject{Attributes: map[string]dschema.Attribute{
+				"addr":          dschema.StringAttribute{Computed: true},
+				"af":            dschema.StringAttribute{Computed: true, Description: `"ipv4" or "ipv6".`},
+				"active":        dschema.BoolAttribute{Computed: true},
+				"reachable":     dschema.BoolAttribute{Computed: true},
+				"last_activity": dschema.Int64Attribute{Computed: true, Description: "Unix time of the last activity on this address."},
+			}},
+		},
+		"active":         dschema.BoolAttribute{Computed: true, Description: "Whether the host is currently active."},
+		"reachable":      dschema.BoolAttribute{Computed: true, Description: "Whether the host answered the last reachability check."},
+		"last_activity":  dschema.Int64Attribute{Computed: true, Description: "Unix time of the last activity."},
+		"first_activity": dschema.Int64Attribute{Computed: true, Description: "Unix time the host was first seen."},
+	}
+}
+
+func (d *lanHostsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(d.client, "freebox_lan_hosts", apiMinLanBrowser, &resp.Diagnostics)
+	}
+}
+
+func (d *lanHostsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	var cfg lanHostsDSModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ifaces := []string{cfg.Interface.ValueString()}
+	if cfg.Interface.IsNull() {
+		list, err := d.client.ListLanInterfaces(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+		ifaces = ifaces[:0]
+		for _, i := range list {
+			ifaces = append(ifaces, i.Name)
+		}
+	}
+
+	out := cfg
+	out.Id = types.StringValue("lan_hosts")
+	out.Hosts = []lanHostItem{}
+	for _, iface := range ifaces {
+		hosts, err := d.client.ListLanHosts(ctx, iface)
+		if err != nil {
+			lanHostsErrors.addError(&resp.Diagnostics, "API error", err)
+			return
+		}
+		for _, h := range hosts {
+			if !cfg.Active.IsNull() && h.Active != cfg.Active.ValueBool() {
+				continue
+			}
+			if !cfg.HostType.IsNull() && h.HostType != cfg.HostType.ValueString() {
+				continue
+			}
+			out.Hosts = append(out.Hosts, lanHostToItem(iface, h))
+		}
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &out)...)
+}
+
+func lanHostToItem(iface string, h api.LanHost) lanHostItem {
+	item := lanHostItem{
+		Id:               types.StringValue(h.ID),
+		Interface:        types.StringValue(iface),
+		PrimaryName:      stringOrNull(h.PrimaryName),
+		HostType:         stringOrNull(h.HostType),
+		VendorName:       stringOrNull(h.VendorName),
+		L2Ident:          lanHostL2Item{Id: stringOrNull(h.L2Ident.ID), Type: stringOrNull(h.L2Ident.Type)},
+		L3Connectivities: make([]lanHostL3Item, 0, len(h.L3Connectivities)),
+		Active:           types.BoolValue(h.Active),
+		Reachable:        types.BoolValue(h.Reachable),
+		LastActivity:     types.Int64Value(h.LastActivity),
+		FirstActivity:    types.Int64Value(h.FirstActivity),
+	}
+	for _, c := range h.L3Connectivities {
+		item.L3Connectivities = append(item.L3Connectivities, lanHostL3Item{
+			Addr: types.StringValue(c.Addr), Af: types.StringValue(c.Af), Active: types.BoolValue(c.Active), Reachable: types.BoolValue(c.Reachable), LastActivity: types.Int64Value(c.LastActivity),
+		})
+	}
+	return item
+}
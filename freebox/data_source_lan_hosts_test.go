@@ -0,0 +1,58 @@
+package freebox
+
+import (
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccLanHostsDataSource(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "nas", HostType: "nas", Active: true,
+		L2Ident:          api.LanHostL2Ident{ID: "AA:BB:CC:DD:EE:42"},
+		L3Connectivities: []api.LanHostL3Connectivity{{Addr: "192.168.1.42", Af: "ipv4", Active: true}},
+	})
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "old-pc", HostType: "workstation", L2Ident: api.LanHostL2Ident{ID: "AA:BB:CC:DD:EE:43"}})
+	srv.PutLanHost("wifiguest", api.LanHost{PrimaryName: "phone", HostType: "smartphone", Active: true, L2Ident: api.LanHostL2Ident{ID: "AA:BB:CC:DD:EE:44"}})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, `
+data "freebox_lan_hosts" "all" {}
+
+data "freebox_lan_hosts" "pub" {
+  interface = "pub"
+}
+
+data "freebox_lan_hosts" "active" {
+  active = true
+}
+
+data "freebox_lan_hosts" "workstations" {
+  host_type = "workstation"
+}
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.all", "id", "lan_hosts"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.all", "hosts.#", "3"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.all", "hosts.2.interface", "wifiguest"),
+
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.pub", "hosts.#", "2"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.pub", "hosts.0.id", "ether-aa:bb:cc:dd:ee:42"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.pub", "hosts.0.primary_name", "nas"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.pub", "hosts.0.l2ident.id", "AA:BB:CC:DD:EE:42"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.pub", "hosts.0.l3connectivities.0.addr", "192.168.1.42"),
+
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.active", "hosts.#", "2"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.active", "hosts.1.primary_name", "phone"),
+
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.workstations", "hosts.#", "1"),
+					resource.TestCheckResourceAttr("data.freebox_lan_hosts.workstations", "hosts.0.primary_name", "old-pc"),
+				),
+			},
+		},
+	})
+}
@@ -2,11 +2,8 @@ package freebox
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -22,7 +19,7 @@ var (
 func NewPortForwardingsDataSource() datasource.DataSource { return &portForwardsDataSource{} }
 
 // Data source
-type portForwardsDataSource struct{ client *Client }
+type portForwardsDataSource struct{ client *api.Client }
 
 // TF models
 type pfItemOut struct {
@@ -77,9 +74,10 @@ func (d *portForwardsDataSource) Schema(_ context.Context, _ datasource.SchemaRe
 	}
 }
 
-func (d *portForwardsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+func (d *portForwardsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
 	if req.ProviderData != nil {
-		d.client = req.ProviderData.(*Client)
+		d.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(d.client, "freebox_port_forwardings", apiMinPortForward, &resp.Diagnostics)
 	}
 }
 
@@ -90,33 +88,15 @@ func (d *portForwardsDataSource) Read(ctx context.Context, _ datasource.ReadRequ
 	}
 
 	// GET /fw/redir/
-	hreq, _ := d.client.newRequest(ctx, http.MethodGet, "/fw/redir/", nil)
-	hres, err := d.client.http.Do(hreq)
+	forwards, err := d.client.ListPortForwards(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	defer hres.Body.Close()
-
-	if hres.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(hres.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", hres.StatusCode, string(body)))
-		return
-	}
-
-	var env apiEnvelope[[]apiPortForward]
-	if err := json.NewDecoder(hres.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
-		return
-	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", env.Msg)
-		return
-	}
 
 	out := pfDSModel{Id: types.StringValue("port_forwardings")}
-	out.Forwards = make([]pfItemOut, 0, len(env.Result))
-	for _, pf := range env.Result {
+	out.Forwards = make([]pfItemOut, 0, len(forwards))
+	for _, pf := range forwards {
 		out.Forwards = append(out.Forwards, pfItemOut{
 			ID:           types.Int64Value(int64(pf.ID)),
 			Enabled:      types.BoolValue(pf.Enabled),
@@ -125,7 +105,7 @@ func (d *portForwardsDataSource) Read(ctx context.Context, _ datasource.ReadRequ
 			WanPortEnd:   types.Int64Value(int64(pf.WanPortEnd)),
 			LanIP:        types.StringValue(pf.LanIP),
 			LanPort:      types.Int64Value(int64(pf.LanPort)),
-			SrcIP:        stringOrNull(pf.SrcIP),
+			SrcIP:        srcIPValue(pf.SrcIP),
 			Comment:      stringOrNull(pf.Comment),
 			Hostname:     stringOrNull(pf.Hostname),
 		})
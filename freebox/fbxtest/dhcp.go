@@ -0,0 +1,311 @@
+package fbxtest
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+)
+
+// dnsSlots is the number of DNS entries the box always returns.
+const dnsSlots = 5
+
+// DhcpConfig returns the current DHCP server configuration.
+func (s *Server) DhcpConfig() api.DhcpConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.dhcp
+	c.DNS = append([]string(nil), s.dhcp.DNS...)
+	return c
+}
+
+// SetDhcpConfig replaces the DHCP server configuration.
+func (s *Server) SetDhcpConfig(c api.DhcpConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.DNS = padDNS(c.DNS)
+	s.dhcp = c
+}
+
+// StaticLeases returns the static leases, in creation order.
+func (s *Server) StaticLeases() []api.StaticLease {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.StaticLease(nil), s.leases...)
+}
+
+// PutStaticLease adds l, or replaces the lease with the same MAC.
+func (s *Server) PutStaticLease(l api.StaticLease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l.Mac = api.NormalizeMAC(l.Mac)
+	l.ID = l.Mac
+	if i := s.leaseIndex(l.Mac); i >= 0 {
+		s.leases[i] = l
+		return
+	}
+	s.leases = append(s.leases, l)
+}
+
+// DeleteStaticLease removes the lease with the given MAC, if any.
+func (s *Server) DeleteStaticLease(mac string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.leaseIndex(api.NormalizeMAC(mac)); i >= 0 {
+		s.leases = append(s.leases[:i], s.leases[i+1:]...)
+	}
+}
+
+// DynamicLeases returns the addresses currently handed out by the DHCP
+// server.
+func (s *Server) DynamicLeases() []api.DynamicLease {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.DynamicLease(nil), s.dynamic...)
+}
+
+// PutDynamicLease adds l, or replaces the dynamic lease with the same MAC, as
+// if the device had just asked the DHCP server for an address. is_static,
+// hostname and host are filled in when listed.
+func (s *Server) PutDynamicLease(l api.DynamicLease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l.Mac = api.NormalizeMAC(l.Mac)
+	for i := range s.dynamic {
+		if s.dynamic[i].Mac == l.Mac {
+			s.dynamic[i] = l
+			return
+		}
+	}
+	s.dynamic = append(s.dynamic, l)
+}
+
+// DeleteDynamicLease removes the dynamic lease of mac, as if it had expired.
+func (s *Server) DeleteDynamicLease(mac string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mac = api.NormalizeMAC(mac)
+	for i := range s.dynamic {
+		if s.dynamic[i].Mac == mac {
+			s.dynamic = append(s.dynamic[:i], s.dynamic[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Server) leaseIndex(mac string) int {
+	for i := range s.leases {
+		if s.leases[i].Mac == mac {
+			return i
+		}
+	}
+	return -1
+}
+
+// withHost fills the read-only hostname and host of l from the LAN browser,
+// as the box does. Callers must hold s.mu.
+func (s *Server) withHost(l api.StaticLease) api.StaticLease {
+	if h := s.hostByMAC(l.Mac); h != nil {
+		l.Hostname = h.PrimaryName
+		host := *h
+		l.Host = &host
+	}
+	return l
+}
+
+func padDNS(dns []string) []string {
+	out := make([]string, dnsSlots)
+	copy(out, dns)
+	return out
+}
+
+// inLAN reports whether ip belongs to the LAN defined by the DHCP gateway and
+// netmask. Callers must hold s.mu.
+func (s *Server) inLAN(ip string) bool {
+	addr := net.ParseIP(ip).To4()
+	gw := net.ParseIP(s.dhcp.Gateway).To4()
+	mask := net.IPMask(net.ParseIP(s.dhcp.Netmask).To4())
+	if addr == nil || gw == nil || len(mask) != 4 {
+		return false
+	}
+	return addr.Mask(mask).Equal(gw.Mask(mask))
+}
+
+func ipLess(a, b string) bool {
+	x, y := net.ParseIP(a).To4(), net.ParseIP(b).To4()
+	for i := range x {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return false
+}
+
+// ---------- /dhcp/config/ ----------
+
+func (s *Server) handleDhcpConfig(w http.ResponseWriter, r *http.Request, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeResult(w, s.dhcp)
+	case http.MethodPut:
+		// Like the box, only the fields present in the body are changed.
+		var patch map[string]json.RawMessage
+		if !decode(w, r, &patch) {
+			return
+		}
+		next := s.dhcp
+		b, _ := json.Marshal(next)
+		var merged map[string]json.RawMessage
+		_ = json.Unmarshal(b, &merged)
+		for k, v := range patch {
+			if k == "gateway" || k == "netmask" {
+				continue // read-only, owned by /lan/config/
+			}
+			merged[k] = v
+		}
+		b, _ = json.Marshal(merged)
+		if err := json.Unmarshal(b, &next); err != nil {
+			writeError(w, http.StatusBadRequest, "inval", "Invalid argument: "+err.Error())
+			return
+		}
+
+		if next.IPRangeStart != "" || next.IPRangeEnd != "" {
+			if !s.inLAN(next.IPRangeStart) || !s.inLAN(next.IPRangeEnd) {
+				writeError(w, http.StatusBadRequest, "inval_ip_range_net", "IP range & netmask mismatch")
+				return
+			}
+			if ipLess(next.IPRangeEnd, next.IPRangeStart) {
+				writeError(w, http.StatusBadRequest, "inval_ip_range", "Invalid IP range")
+				return
+			}
+		}
+		for _, d := range next.DNS {
+			if d != "" && net.ParseIP(d) == nil {
+				writeError(w, http.StatusBadRequest, "inval", "Invalid dns server "+d)
+				return
+			}
+		}
+		if len(next.DNS) > dnsSlots {
+			writeError(w, http.StatusBadRequest, "inval", "Too many dns servers")
+			return
+		}
+		next.DNS = padDNS(next.DNS)
+		s.dhcp = next
+		writeResult(w, s.dhcp)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// ---------- /dhcp/static_lease/ ----------
+
+func (s *Server) handleStaticLease(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == "" {
+		switch r.Method {
+		case http.MethodGet:
+			out := make([]api.StaticLease, 0, len(s.leases))
+			for _, l := range s.leases {
+				out = append(out, s.withHost(l))
+			}
+			writeResult(w, out)
+		case http.MethodPost:
+			var l api.StaticLease
+			if !decode(w, r, &l) {
+				return
+			}
+			mac := api.NormalizeMAC(l.Mac)
+			if mac == "" {
+				writeError(w, http.StatusBadRequest, "inval", "Invalid mac address")
+				return
+			}
+			if !s.inLAN(l.IP) {
+				writeError(w, http.StatusBadRequest, "inval", "Invalid ip: not in the LAN subnet")
+				return
+			}
+			for _, other := range s.leases {
+				if other.Mac == mac || other.IP == l.IP {
+					writeError(w, http.StatusBadRequest, "exist", "Static lease already exists")
+					return
+				}
+			}
+			lease := api.StaticLease{ID: mac, Mac: mac, IP: l.IP, Comment: strings.TrimSpace(l.Comment)}
+			s.leases = append(s.leases, lease)
+			writeResult(w, s.withHost(lease))
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	i := s.leaseIndex(api.NormalizeMAC(id))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "noent", "No such static lease")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeResult(w, s.withHost(s.leases[i]))
+	case http.MethodPut:
+		var u api.StaticLeaseUpdate
+		if !decode(w, r, &u) {
+			return
+		}
+		next := s.leases[i]
+		if u.IP != nil {
+			if !s.inLAN(*u.IP) {
+				writeError(w, http.StatusBadRequest, "inval", "Invalid ip: not in the LAN subnet")
+				return
+			}
+			for j, other := range s.leases {
+				if j != i && other.IP == *u.IP {
+					writeError(w, http.StatusBadRequest, "exist", "Static lease already exists")
+					return
+				}
+			}
+			next.IP = *u.IP
+		}
+		if u.Comment != nil {
+			next.Comment = strings.TrimSpace(*u.Comment)
+		}
+		s.leases[i] = next
+		writeResult(w, s.withHost(next))
+	case http.MethodDelete:
+		s.leases = append(s.leases[:i], s.leases[i+1:]...)
+		writeResult(w, nil)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// ---------- /dhcp/dynamic_lease/ ----------
+
+func (s *Server) handleDynamicLease(w http.ResponseWriter, r *http.Request, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+	out := make([]api.DynamicLease, 0, len(s.dynamic))
+	for _, l := range s.dynamic {
+		l.IsStatic = s.leaseIndex(l.Mac) >= 0
+		if h := s.hostByMAC(l.Mac); h != nil {
+			host := *h
+			l.Host = &host
+			if l.Hostname == "" {
+				l.Hostname = h.PrimaryName
+			}
+		}
+		out = append(out, l)
+	}
+	writeResult(w, out)
+}
@@ -0,0 +1,175 @@
+package fbxtest
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+)
+
+// PortForwards returns the port forwarding rules, in creation order.
+func (s *Server) PortForwards() []api.PortForward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.withHostnames(s.redirs)
+}
+
+// PutPortForward adds pf, or replaces the rule with the same id. A zero id
+// gets the next free one, which is returned.
+func (s *Server) PutPortForward(pf api.PortForward) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pf.ID == 0 {
+		pf.ID = s.nextID
+	}
+	if pf.ID >= s.nextID {
+		s.nextID = pf.ID + 1
+	}
+	if i := s.redirIndex(pf.ID); i >= 0 {
+		s.redirs[i] = pf
+	} else {
+		s.redirs = append(s.redirs, pf)
+	}
+	return pf.ID
+}
+
+// DeletePortForward removes rule id, if any.
+func (s *Server) DeletePortForward(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.redirIndex(id); i >= 0 {
+		s.redirs = append(s.redirs[:i], s.redirs[i+1:]...)
+	}
+}
+
+// withHostname fills the read-only hostname of pf from the LAN browser host
+// of its lan_ip, as the box does. Callers must hold s.mu.
+func (s *Server) withHostname(pf api.PortForward) api.PortForward {
+	pf.Hostname = ""
+	if h := s.hostByIP(pf.LanIP); h != nil {
+		pf.Hostname = h.PrimaryName
+	}
+	return pf
+}
+
+// withHostnames applies withHostname to every rule. Callers must hold s.mu.
+func (s *Server) withHostnames(rules []api.PortForward) []api.PortForward {
+	out := make([]api.PortForward, len(rules))
+	for i, pf := range rules {
+		out[i] = s.withHostname(pf)
+	}
+	return out
+}
+
+func (s *Server) redirIndex(id int) int {
+	for i := range s.redirs {
+		if s.redirs[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateRedir checks pf like the box does and returns the error code and
+// message to answer with, or "" when pf is valid. skip is the id of the rule
+// being updated. Callers must hold s.mu.
+func (s *Server) validateRedir(pf *api.PortForward, skip int) (code, msg string) {
+	if pf.IpProto != "tcp" && pf.IpProto != "udp" {
+		return "inval", "Invalid ip_proto: must be tcp or udp"
+	}
+	if pf.WanPortStart < 1 || pf.WanPortStart > 65535 || pf.WanPortEnd < 1 || pf.WanPortEnd > 65535 {
+		return "inval", "Invalid wan_port_start or wan_port_end: must be between 1 and 65535"
+	}
+	if pf.WanPortEnd < pf.WanPortStart {
+		return "inval", "Invalid wan_port_end: lower than wan_port_start"
+	}
+	if pf.LanPort < 1 || pf.LanPort+pf.WanPortEnd-pf.WanPortStart > 65535 {
+		return "inval", "Invalid lan_port: must be between 1 and 65535"
+	}
+	if !s.inLAN(pf.LanIP) {
+		return "inval", "Invalid lan_ip: not in the LAN subnet"
+	}
+	if pf.SrcIP == "" {
+		pf.SrcIP = "0.0.0.0"
+	}
+	if net.ParseIP(pf.SrcIP).To4() == nil {
+		return "inval", "Invalid src_ip"
+	}
+	for _, other := range s.redirs {
+		if other.ID != skip && other.IpProto == pf.IpProto &&
+			pf.WanPortStart <= other.WanPortEnd && other.WanPortStart <= pf.WanPortEnd {
+			return "exist", "Port forwarding already exists on this WAN port"
+		}
+	}
+	pf.Comment = strings.TrimSpace(pf.Comment)
+	return "", ""
+}
+
+// ---------- /fw/redir/ ----------
+
+func (s *Server) handleRedir(w http.ResponseWriter, r *http.Request, rest string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			writeResult(w, s.withHostnames(s.redirs))
+		case http.MethodPost:
+			var pf api.PortForward
+			if !decode(w, r, &pf) {
+				return
+			}
+			if code, msg := s.validateRedir(&pf, 0); code != "" {
+				writeError(w, http.StatusBadRequest, code, msg)
+				return
+			}
+			pf.ID = s.nextID
+			s.nextID++
+			s.redirs = append(s.redirs, pf)
+			writeResult(w, s.withHostname(pf))
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(rest)
+	i := -1
+	if err == nil {
+		i = s.redirIndex(id)
+	}
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "noent", "No such port forwarding")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeResult(w, s.withHostname(s.redirs[i]))
+	case http.MethodPut:
+		// Partial update, as on the box: fields left out keep their value.
+		pf := s.redirs[i]
+		pf.ID = 0
+		if !decode(w, r, &pf) {
+			return
+		}
+		if pf.ID != 0 && pf.ID != id {
+			writeError(w, http.StatusBadRequest, "inval", "Invalid id: does not match the URL")
+			return
+		}
+		pf.ID = id
+		if code, msg := s.validateRedir(&pf, id); code != "" {
+			writeError(w, http.StatusBadRequest, code, msg)
+			return
+		}
+		s.redirs[i] = pf
+		writeResult(w, s.withHostname(pf))
+	case http.MethodDelete:
+		s.redirs = append(s.redirs[:i], s.redirs[i+1:]...)
+		writeResult(w, nil)
+	default:
+		methodNotAllowed(w)
+	}
+}
@@ -0,0 +1,277 @@
+package fbxtest
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+)
+
+// LanConfig returns the LAN configuration.
+func (s *Server) LanConfig() api.LanConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lan
+}
+
+// SetLanConfig replaces the LAN configuration. The DHCP gateway follows the
+// LAN address, as on the box.
+func (s *Server) SetLanConfig(c api.LanConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readdress(c.IP)
+	s.lan = c
+}
+
+var dnsName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// validateLan checks c like the box does and returns the error message to
+// answer with, or "" when c is valid.
+func validateLan(c api.LanConfig) string {
+	ip := net.ParseIP(c.IP).To4()
+	switch {
+	case ip == nil || !ip.IsPrivate():
+		return "Invalid ip: must be a private IPv4 address"
+	case c.Mode != api.LanModeRouter && c.Mode != api.LanModeBridge:
+		return "Invalid mode: must be router or bridge"
+	case c.Name == "":
+		return "Invalid name: must not be empty"
+	case !dnsName.MatchString(c.NameDNS):
+		return "Invalid name_dns: must be a valid DNS label"
+	case !dnsName.MatchString(c.NameMDNS):
+		return "Invalid name_mdns: must be a valid DNS label"
+	case c.NameNetbios == "" || len(c.NameNetbios) > 15:
+		return "Invalid name_netbios: must be 1 to 15 characters"
+	}
+	return ""
+}
+
+// readdress moves the LAN to the /24 of ip: the DHCP gateway becomes ip, and
+// the DHCP range and DNS entries pointing at the old gateway follow, keeping
+// their host part. Callers must hold s.mu.
+func (s *Server) readdress(ip string) {
+	old := s.dhcp.Gateway
+	if ip == "" || ip == old {
+		return
+	}
+	move := func(addr string) string {
+		a, n := net.ParseIP(addr).To4(), net.ParseIP(ip).To4()
+		if a == nil || n == nil {
+			return addr
+		}
+		return net.IPv4(n[0], n[1], n[2], a[3]).String()
+	}
+	s.dhcp.Gateway = ip
+	s.dhcp.IPRangeStart = move(s.dhcp.IPRangeStart)
+	s.dhcp.IPRangeEnd = move(s.dhcp.IPRangeEnd)
+	for i, d := range s.dhcp.DNS {
+		if d == old {
+			s.dhcp.DNS[i] = ip
+		}
+	}
+}
+
+// ---------- /lan/config/ ----------
+
+func (s *Server) handleLanConfig(w http.ResponseWriter, r *http.Request, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		writeResult(w, s.lan)
+	case http.MethodPut:
+		// Like the box, only the fields present in the body are changed.
+		var patch map[string]json.RawMessage
+		if !decode(w, r, &patch) {
+			return
+		}
+		next := s.lan
+		b, _ := json.Marshal(next)
+		var merged map[string]json.RawMessage
+		_ = json.Unmarshal(b, &merged)
+		for k, v := range patch {
+			merged[k] = v
+		}
+		b, _ = json.Marshal(merged)
+		if err := json.Unmarshal(b, &next); err != nil {
+			writeError(w, http.StatusBadRequest, "inval", "Invalid argument: "+err.Error())
+			return
+		}
+		if msg := validateLan(next); msg != "" {
+			writeError(w, http.StatusBadRequest, "inval", msg)
+			return
+		}
+		s.readdress(next.IP)
+		s.lan = next
+		writeResult(w, s.lan)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// LanHosts returns the hosts seen on iface, in discovery order.
+func (s *Server) LanHosts(iface string) []api.LanHost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.LanHost(nil), s.hosts[iface]...)
+}
+
+// PutLanHost adds h to iface, creating the interface if needed, or replaces
+// the host with the same id. An empty id is derived from the MAC address in
+// h.L2Ident, as "ether-aa:bb:cc:dd:ee:ff".
+func (s *Server) PutLanHost(iface string, h api.LanHost) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mac := api.NormalizeMAC(h.L2Ident.ID); mac != "" {
+		h.L2Ident.ID = mac
+	}
+	if h.ID == "" {
+		h.ID = "ether-" + strings.ToLower(h.L2Ident.ID)
+	}
+	if h.L2Ident.Type == "" {
+		h.L2Ident.Type = "mac_address"
+	}
+	if i := s.hostIndex(iface, h.ID); i >= 0 {
+		s.hosts[iface][i] = h
+		return
+	}
+	s.hosts[iface] = append(s.hosts[iface], h)
+}
+
+// DeleteLanHost removes host id from iface, if any.
+func (s *Server) DeleteLanHost(iface, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.hostIndex(iface, id); i >= 0 {
+		s.hosts[iface] = append(s.hosts[iface][:i], s.hosts[iface][i+1:]...)
+	}
+}
+
+func (s *Server) hostIndex(iface, id string) int {
+	for i := range s.hosts[iface] {
+		if s.hosts[iface][i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ---------- /lan/browser/ ----------
+
+func (s *Server) handleLanBrowser(w http.ResponseWriter, r *http.Request, rest string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rest == "interfaces" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		names := make([]string, 0, len(s.hosts))
+		for name := range s.hosts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out := make([]api.LanInterface, 0, len(names))
+		for _, name := range names {
+			out = append(out, api.LanInterface{Name: name, HostCount: len(s.hosts[name])})
+		}
+		writeResult(w, out)
+		return
+	}
+
+	iface, id, _ := strings.Cut(rest, "/")
+	hosts, ok := s.hosts[iface]
+	if !ok {
+		writeError(w, http.StatusNotFound, "nodev", "Invalid interface")
+		return
+	}
+	if id == "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		writeResult(w, append([]api.LanHost{}, hosts...))
+		return
+	}
+	i := s.hostIndex(iface, id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "noent", "Invalid host ID")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeResult(w, hosts[i])
+	case http.MethodPut:
+		var u api.LanHostUpdate
+		if !decode(w, r, &u) {
+			return
+		}
+		next := hosts[i]
+		if u.PrimaryName != nil {
+			if strings.TrimSpace(*u.PrimaryName) == "" {
+				writeError(w, http.StatusBadRequest, "inval", "Invalid primary_name: must not be empty")
+				return
+			}
+			next.PrimaryName = *u.PrimaryName
+			next.PrimaryNameManual = true
+		}
+		if u.HostType != nil {
+			if !validHostType(*u.HostType) {
+				writeError(w, http.StatusBadRequest, "inval", "Invalid host_type "+*u.HostType)
+				return
+			}
+			next.HostType = *u.HostType
+		}
+		if u.Persistent != nil {
+			next.Persistent = *u.Persistent
+		}
+		hosts[i] = next
+		writeResult(w, next)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func validHostType(t string) bool {
+	for _, ok := range api.LanHostTypes {
+		if t == ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hostByMAC returns the host of mac on the main LAN, or nil. Callers must
+// hold s.mu.
+func (s *Server) hostByMAC(mac string) *api.LanHost {
+	for i := range s.hosts[api.LanMainInterface] {
+		if s.hosts[api.LanMainInterface][i].L2Ident.ID == mac {
+			return &s.hosts[api.LanMainInterface][i]
+		}
+	}
+	return nil
+}
+
+// hostByIP returns the main LAN host using ip, from its addresses or its
+// static lease, or nil. Callers must hold s.mu.
+func (s *Server) hostByIP(ip string) *api.LanHost {
+	for i, h := range s.hosts[api.LanMainInterface] {
+		for _, c := range h.L3Connectivities {
+			if c.Addr == ip {
+				return &s.hosts[api.LanMainInterface][i]
+			}
+		}
+	}
+	for _, l := range s.leases {
+		if l.IP == ip {
+			return s.hostByMAC(l.Mac)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+// Package fbxtest provides an in-process fake Freebox API for tests.
+//
+// The server emulates the login challenge/session flow, app authorization and
+// the /dhcp/config/, /dhcp/static_lease/, /dhcp/dynamic_lease/, /fw/redir/,
+// /lan/config/ and /lan/browser/ endpoints, answering with the same envelopes
+// and error codes as a real box:
+//
+//	srv := fbxtest.NewServer()
+//	defer srv.Close()
+//	c := api.NewClient(api.Config{BaseURL: srv.BaseURL(), AppID: srv.AppID, AppToken: srv.AppToken})
+//
+// State can be read and changed directly (StaticLeases, PutStaticLease, ...)
+// to simulate drift made outside Terraform.
+package fbxtest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+)
+
+// Defaults used by NewServer.
+const (
+	DefaultAppID    = "fr.freebox.terraform"
+	DefaultAppToken = "fbxtest-app-token"
+	DefaultVersion  = "8.0"
+)
+
+// Server is a fake Freebox. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// AppID and AppToken are the credentials of the pre-registered app.
+	AppID    string
+	AppToken string
+
+	mu          sync.Mutex
+	version     api.APIVersion
+	apps        map[string]string // app_id -> app_token
+	permissions map[string]bool
+	challenge   string
+	sessions    map[string]bool
+	authz       map[int]*authorization
+	autoGrant   bool
+	logins      int
+	failures    []failure
+
+	lan     api.LanConfig
+	hosts   map[string][]api.LanHost // interface -> hosts
+	dhcp    api.DhcpConfig
+	leases  []api.StaticLease
+	dynamic []api.DynamicLease
+	redirs  []api.PortForward
+	nextID  int
+}
+
+type failure struct {
+	status int
+	code   string
+}
+
+type authorization struct {
+	appID  string
+	token  string
+	status string
+}
+
+// NewServer starts a fake Freebox serving API v8 on 192.168.1.0/24, with
+// DHCP enabled, no lease or port forwarding, and no host on the "pub" and
+// "wifiguest" LAN browser interfaces.
+func NewServer() *Server {
+	s := &Server{
+		AppID:    DefaultAppID,
+		AppToken: DefaultAppToken,
+		version: api.APIVersion{
+			UID:            "fbxtest",
+			DeviceName:     "Freebox Server",
+			DeviceType:     "FreeboxServer1,2",
+			APIVersion:     DefaultVersion,
+			APIBaseURL:     "/api/",
+			APIDomain:      "fbxtest.fbxos.fr",
+			HTTPSAvailable: true,
+			HTTPSPort:      443,
+			BoxModel:       "fbxgw-r2/full",
+			BoxModelName:   "Freebox v6 (r2)",
+		},
+		permissions: map[string]bool{"settings": true, "explorer": true, "downloader": true, "vm": false},
+		sessions:    map[string]bool{},
+		authz:       map[int]*authorization{},
+		lan: api.LanConfig{
+			IP:          "192.168.1.254",
+			Name:        "Freebox Server",
+			NameDNS:     "freebox-server",
+			NameMDNS:    "Freebox-Server",
+			NameNetbios: "Freebox_Server",
+			Mode:        api.LanModeRouter,
+		},
+		hosts: map[string][]api.LanHost{"pub": nil, "wifiguest": nil},
+		dhcp: api.DhcpConfig{
+			Enabled:      true,
+			StickyAssign: true,
+			Gateway:      "192.168.1.254",
+			Netmask:      "255.255.255.0",
+			IPRangeStart: "192.168.1.1",
+			IPRangeEnd:   "192.168.1.50",
+			DNS:          []string{"192.168.1.254", "", "", "", ""},
+		},
+		nextID: 1,
+	}
+	s.apps = map[string]string{s.AppID: s.AppToken}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the versioned API URL to give to the client or provider.
+func (s *Server) BaseURL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version.BaseURL(s.URL)
+}
+
+// ProviderConfig returns a provider block pointing at the server.
+func (s *Server) ProviderConfig() string {
+	return fmt.Sprintf("provider \"freebox\" {\n  base_url  = %q\n  app_id    = %q\n  app_token = %q\n}\n", s.URL, s.AppID, s.AppToken)
+}
+
+// SetAPIVersion changes the version reported by /api_version, e.g. "6.0".
+func (s *Server) SetAPIVersion(v string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version.APIVersion = v
+}
+
+// SetPermission grants or revokes an app permission for new sessions.
+func (s *Server) SetPermission(name string, granted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.permissions[name] = granted
+}
+
+// ExpireSessions invalidates every open session, as the box does after its
+// session timeout.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]bool{}
+}
+
+// Logins returns how many sessions have been opened.
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// AutoGrant makes app authorization requests granted on their first poll,
+// as if someone approved them on the front panel.
+func (s *Server) AutoGrant(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoGrant = on
+}
+
+// SetAuthorizationStatus sets the status of the request tracked by trackID
+// ("granted", "denied", "timeout", ...).
+func (s *Server) SetAuthorizationStatus(trackID int, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.authz[trackID]; ok {
+		a.status = status
+		if status == api.AuthStatusGranted {
+			s.apps[a.appID] = a.token
+		}
+	}
+}
+
+// FailNext makes the next n API calls (outside login) fail with the given HTTP
+// status and error code, e.g. (2, 503, "busy") to exercise retries.
+func (s *Server) FailNext(n, status int, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, failure{status, code})
+	}
+}
+
+// nextFailure pops the next injected failure, if any.
+func (s *Server) nextFailure() (failure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.failures) == 0 {
+		return failure{}, false
+	}
+	f := s.failures[0]
+	s.failures = s.failures[1:]
+	return f, true
+}
+
+// ---------- HTTP plumbing ----------
+
+var apiPath = regexp.MustCompile(`^/api/v\d+(/.*)$`)
+
+// route is a handler for one API endpoint; rest holds the path after the
+// endpoint prefix (e.g. the lease id).
+type route struct {
+	prefix  string
+	handler func(s *Server, w http.ResponseWriter, r *http.Request, rest string)
+}
+
+var routes = []route{
+	{"/dhcp/config/", (*Server).handleDhcpConfig},
+	{"/dhcp/static_lease/", (*Server).handleStaticLease},
+	{"/dhcp/dynamic_lease/", (*Server).handleDynamicLease},
+	{"/fw/redir/", (*Server).handleRedir},
+	{"/lan/config/", (*Server).handleLanConfig},
+	{"/lan/browser/", (*Server).handleLanBrowser},
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api_version" {
+		s.mu.Lock()
+		v := s.version
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+
+	m := apiPath.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		writeError(w, http.StatusNotFound, "invalid_api_version", "Invalid API base URL or version")
+		return
+	}
+	p := m[1]
+
+	switch {
+	case p == "/login/" || p == "/login":
+		s.handleChallenge(w)
+		return
+	case p == "/login/session/" || p == "/login/session":
+		s.handleSession(w, r)
+		return
+	case strings.HasPrefix(p, "/login/authorize"):
+		s.handleAuthorize(w, r, strings.Trim(strings.TrimPrefix(p, "/login/authorize"), "/"))
+		return
+	}
+
+	if !s.validSession(r.Header.Get("X-Fbx-App-Auth")) {
+		writeError(w, http.StatusForbidden, "auth_required", "Invalid session token, or no session token sent")
+		return
+	}
+	if f, ok := s.nextFailure(); ok {
+		writeError(w, f.status, f.code, "Injected failure")
+		return
+	}
+	for _, rt := range routes {
+		if strings.HasPrefix(p, rt.prefix) || p+"/" == rt.prefix {
+			rt.handler(s, w, r, strings.Trim(strings.TrimPrefix(p, rt.prefix), "/"))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "invalid_request", "Unknown endpoint "+p)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	if result == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"success": true})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"success": true, "result": result})
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, map[string]any{"success": false, "error_code": code, "msg": msg})
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "invalid_request", "Method not allowed")
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ---------- login ----------
+
+func (s *Server) validSession(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return token != "" && s.sessions[token]
+}
+
+func (s *Server) handleChallenge(w http.ResponseWriter) {
+	s.mu.Lock()
+	s.challenge = randomHex(16)
+	challenge := s.challenge
+	s.mu.Unlock()
+	writeResult(w, map[string]any{"logged_in": false, "challenge": challenge})
+}
+
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	var body struct {
+		AppID    string `json:"app_id"`
+		Password string `json:"password"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.apps[body.AppID]
+	if !ok {
+		writeError(w, http.StatusForbidden, "invalid_token", "The app token you are trying to use is invalid or has been revoked")
+		return
+	}
+	mac := hmac.New(sha1.New, []byte(token))
+	mac.Write([]byte(s.challenge))
+	if s.challenge == "" || body.Password != hex.EncodeToString(mac.Sum(nil)) {
+		writeError(w, http.StatusForbidden, "invalid_token", "The app token you are trying to use is invalid or has been revoked")
+		return
+	}
+
+	session := randomHex(16)
+	s.sessions[session] = true
+	s.challenge = ""
+	s.logins++
+	perms := make(map[string]bool, len(s.permissions))
+	for k, v := range s.permissions {
+		perms[k] = v
+	}
+	writeResult(w, map[string]any{"session_token": session, "challenge": randomHex(16), "permissions": perms})
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request, rest string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rest == "" {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		var app api.AppDescription
+		if !decode(w, r, &app) {
+			return
+		}
+		if app.AppID == "" || app.AppName == "" || app.AppVersion == "" || app.DeviceName == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Missing app_id, app_name, app_version or device_name")
+			return
+		}
+		id := len(s.authz) + 1
+		a := &authorization{appID: app.AppID, token: randomHex(32), status: api.AuthStatusPending}
+		s.authz[id] = a
+		writeResult(w, api.Authorization{AppToken: a.token, TrackID: id})
+		return
+	}
+
+	var id int
+	if _, err := fmt.Sscanf(rest, "%d", &id); err != nil || s.authz[id] == nil {
+		writeResult(w, map[string]string{"status": api.AuthStatusUnknown})
+		return
+	}
+	a := s.authz[id]
+	if s.autoGrant && a.status == api.AuthStatusPending {
+		a.status = api.AuthStatusGranted
+		s.apps[a.appID] = a.token
+	}
+	writeResult(w, map[string]string{"status": a.status, "challenge": randomHex(16)})
+}
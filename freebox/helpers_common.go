@@ -1,6 +1,10 @@
 package freebox
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
 
 // stringOrNull turns an empty string into a Terraform null string
 func stringOrNull(s string) types.String {
@@ -9,3 +13,26 @@ func stringOrNull(s string) types.String {
 	}
 	return types.StringValue(s)
 }
+
+// anySource is the src_ip of port forwardings open to every source.
+const anySource = "0.0.0.0"
+
+// srcIPValue reads a port forwarding src_ip; the box may leave it empty for
+// rules open to any source, which is written 0.0.0.0 on our side.
+func srcIPValue(s string) types.String {
+	if s == "" {
+		s = anySource
+	}
+	return types.StringValue(s)
+}
+
+// commentValue reads a comment returned by the box, which trims it. prior is
+// the planned or stored value: when it only differs by surrounding spaces, or
+// is "" for an empty comment, it is kept as written so that neither form
+// shows as a diff. Otherwise an empty comment is null.
+func commentValue(box string, prior types.String) types.String {
+	if !prior.IsNull() && !prior.IsUnknown() && strings.TrimSpace(prior.ValueString()) == box {
+		return prior
+	}
+	return stringOrNull(box)
+}
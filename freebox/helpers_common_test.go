@@ -0,0 +1,52 @@
+package freebox
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCommentValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		box   string
+		prior types.String
+		want  types.String
+	}{
+		{"empty, prior null", "", types.StringNull(), types.StringNull()},
+		{"empty, prior unknown", "", types.StringUnknown(), types.StringNull()},
+		{"empty, prior empty", "", types.StringValue(""), types.StringValue("")},
+		{"empty, prior blank", "", types.StringValue("  "), types.StringValue("  ")},
+		{"empty, prior removed on the box", "", types.StringValue("web"), types.StringNull()},
+		{"set, prior null", "web", types.StringNull(), types.StringValue("web")},
+		{"set, prior unknown", "web", types.StringUnknown(), types.StringValue("web")},
+		{"set, prior equal", "web", types.StringValue("web"), types.StringValue("web")},
+		{"set, prior trimmed by the box", "web", types.StringValue("  web\n"), types.StringValue("  web\n")},
+		{"set, prior empty", "web", types.StringValue(""), types.StringValue("web")},
+		{"set, prior changed on the box", "web", types.StringValue("ssh"), types.StringValue("web")},
+		{"inner spaces kept", "my web", types.StringValue("my  web"), types.StringValue("my web")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commentValue(tt.box, tt.prior); !got.Equal(tt.want) {
+				t.Errorf("commentValue(%q, %s) = %s, want %s", tt.box, tt.prior, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSrcIPValue(t *testing.T) {
+	tests := []struct {
+		box  string
+		want string
+	}{
+		{"", anySource},
+		{anySource, anySource},
+		{"203.0.113.7", "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		if got := srcIPValue(tt.box); !got.Equal(types.StringValue(tt.want)) {
+			t.Errorf("srcIPValue(%q) = %s, want %q", tt.box, got, tt.want)
+		}
+	}
+}
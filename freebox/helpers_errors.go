@@ -0,0 +1,46 @@
+package freebox
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// errorScope tells which attribute a Freebox error refers to, so diagnostics
+// point at the offending argument instead of the whole resource.
+type errorScope struct {
+	// codes maps an error_code to the attribute it always concerns.
+	codes map[string]string
+	// attrs are the user-settable attributes; when the Freebox names one of
+	// them in its message, the diagnostic is attached to it.
+	attrs []string
+}
+
+// addError appends err to diags, scoped to an attribute when one can be told.
+func (s errorScope) addError(diags *diag.Diagnostics, summary string, err error) {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError(summary, err.Error())
+		return
+	}
+	if attr := s.attributeFor(apiErr); attr != "" {
+		diags.AddAttributeError(path.Root(attr), summary, apiErr.Error())
+		return
+	}
+	diags.AddError(summary, apiErr.Error())
+}
+
+func (s errorScope) attributeFor(e *api.APIError) string {
+	if attr, ok := s.codes[e.ErrorCode]; ok {
+		return attr
+	}
+	for _, attr := range s.attrs {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(attr) + `\b`).MatchString(e.Msg) {
+			return attr
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package freebox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func TestErrorScopeAddError(t *testing.T) {
+	scope := errorScope{
+		codes: map[string]string{"inval_ip_range": "ip_range_start"},
+		attrs: []string{"ip", "ip_range_end"},
+	}
+	apiErr := func(code, msg string) error {
+		return &api.APIError{HTTPStatus: 400, ErrorCode: code, Msg: msg, Domain: "dhcp"}
+	}
+	tests := []struct {
+		name   string
+		err    error
+		want   string // attribute, or "" for a resource-level error
+		detail string
+	}{
+		{"not an API error", errors.New("connection refused"), "", "connection refused"},
+		{"mapped code", apiErr("inval_ip_range", "Invalid IP range"), "ip_range_start", "error_code=inval_ip_range: invalid IP range"},
+		{"code wins over the message", apiErr("inval_ip_range", "Invalid ip_range_end"), "ip_range_start", "Invalid ip_range_end"},
+		{"named in the message", apiErr("inval", "Invalid ip: not in the LAN subnet"), "ip", "invalid DHCP setting"},
+		{"whole words only", apiErr("inval", "Invalid ip_range_start"), "", "Invalid ip_range_start"},
+		{"wrapped", fmt.Errorf("update failed: %w", apiErr("inval", "Invalid ip_range_end")), "ip_range_end", "error_code=inval"},
+		{"nothing to tell", apiErr("busy", "Try again later"), "", "the DHCP server is busy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			scope.addError(&diags, "API error", tt.err)
+			if len(diags) != 1 {
+				t.Fatalf("got %d diagnostics, want 1: %v", len(diags), diags)
+			}
+			d := diags[0]
+			if d.Summary() != "API error" || !strings.Contains(d.Detail(), tt.detail) {
+				t.Errorf("diagnostic = %q: %q, want detail containing %q", d.Summary(), d.Detail(), tt.detail)
+			}
+			withPath, ok := d.(diag.DiagnosticWithPath)
+			switch {
+			case tt.want == "" && ok:
+				t.Errorf("attached to %s, want a resource-level error", withPath.Path())
+			case tt.want != "" && !ok:
+				t.Errorf("resource-level error, want it attached to %s", tt.want)
+			case tt.want != "" && !withPath.Path().Equal(path.Root(tt.want)):
+				t.Errorf("attached to %s, want %s", withPath.Path(), tt.want)
+			}
+		})
+	}
+}
+
+// The attributes an errorScope points at must exist in the schema it scopes.
+func TestErrorScopesMatchSchemas(t *testing.T) {
+	ctx := context.Background()
+	resourceAttrs := func(r resource.Resource) map[string]bool {
+		var resp resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &resp)
+		out := map[string]bool{}
+		for name := range resp.Schema.Attributes {
+			out[name] = true
+		}
+		return out
+	}
+	var dsResp datasource.SchemaResponse
+	(&lanHostsDataSource{}).Schema(ctx, datasource.SchemaRequest{}, &dsResp)
+	lanHostsAttrs := map[string]bool{}
+	for name := range dsResp.Schema.Attributes {
+		lanHostsAttrs[name] = true
+	}
+
+	scopes := []struct {
+		name  string
+		scope errorScope
+		attrs map[string]bool
+	}{
+		{"dhcp_config", dhcpConfigErrors, resourceAttrs(&dhcpConfigResource{})},
+		{"dhcp_lease", leaseErrors, resourceAttrs(&dhcpLeaseResource{})},
+		{"port_forwarding", pfErrors, resourceAttrs(&portForwardResource{})},
+		{"lan_config", lanConfigErrors, resourceAttrs(&lanConfigResource{})},
+		{"lan_host", lanHostErrors, resourceAttrs(&lanHostResource{})},
+		{"lan_hosts", lanHostsErrors, lanHostsAttrs},
+	}
+	for _, s := range scopes {
+		for code, attr := range s.scope.codes {
+			if !s.attrs[attr] {
+				t.Errorf("%s: error_code %s points at unknown attribute %q", s.name, code, attr)
+			}
+		}
+		for _, attr := range s.scope.attrs {
+			if !s.attrs[attr] {
+				t.Errorf("%s: unknown attribute %q", s.name, attr)
+			}
+		}
+	}
+}
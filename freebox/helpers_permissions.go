@@ -0,0 +1,52 @@
+package freebox
+
+import (
+	"fmt"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// Freebox app permission needed to change the box configuration.
+const permSettings = "settings"
+
+// permissionLabels are the names Freebox OS shows for each permission in
+// Paramètres de la Freebox > Gestion des accès > Applications.
+var permissionLabels = map[string]string{
+	"settings":   "Modification des réglages de la Freebox",
+	"explorer":   "Accès aux fichiers de la Freebox",
+	"downloader": "Accès au gestionnaire de téléchargements",
+	"vm":         "Contrôle de la VM",
+	"parental":   "Accès au contrôle parental",
+	"contacts":   "Accès à la base de contacts de la Freebox",
+	"calls":      "Accès au journal d'appels",
+	"camera":     "Accès aux caméras",
+	"home":       "Gestion de l'alarme et maison connectée",
+	"player":     "Contrôle du Freebox Player",
+	"pvr":        "Programmation des enregistrements",
+	"profile":    "Gestion des profils utilisateur",
+}
+
+// checkPermission fails a plan that changes typeName when the app token lacks
+// perm, instead of letting apply stop on insufficient_rights. Plans without
+// changes are left alone so read-only tokens can still refresh, and so are
+// destroy plans when deleteWrites is false, as Delete leaves the box as is.
+func checkPermission(c *api.Client, perm, typeName string, deleteWrites bool, req resource.ModifyPlanRequest, diags *diag.Diagnostics) {
+	if c == nil || req.Plan.Raw.Equal(req.State.Raw) || (req.Plan.Raw.IsNull() && !deleteWrites) {
+		return
+	}
+	granted, known := c.HasPermission(perm)
+	if !known || granted {
+		return
+	}
+	label := permissionLabels[perm]
+	if label == "" {
+		label = perm
+	}
+	diags.AddError("Missing Freebox permission",
+		fmt.Sprintf("%s needs the %q permission (%s), which app %s has not been granted. "+
+			"Grant it in Freebox OS: Paramètres de la Freebox > Gestion des accès > Applications, "+
+			"click Editer on the application and tick %q.",
+			typeName, perm, label, c.AppID(), label))
+}
@@ -0,0 +1,106 @@
+package freebox
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// Without the settings permission, plans that would write to the box fail
+// before apply; destroying resources whose Delete writes nothing does not.
+func TestAccPermissionSettings(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "nas", HostType: "nas", L2Ident: api.LanHostL2Ident{ID: testLeaseMAC}})
+	lease := func(ip string) string {
+		return fmt.Sprintf("resource \"freebox_dhcp_lease\" \"test\" {\n  mac = %q\n  ip  = %q\n}\n", testLeaseMAC, ip)
+	}
+	others := fmt.Sprintf(`
+resource "freebox_lan_host" "test" {
+  host_id      = %q
+  primary_name = "nas"
+}
+
+resource "freebox_lan_config" "test" {
+  name_dns = "box"
+}
+
+resource "freebox_dhcp_config" "test" {
+  ip_range_end = "192.168.1.60"
+}
+`, testLanHostID)
+	revoke := func() { srv.SetPermission("settings", false) }
+	missing := regexp.MustCompile(`(?s)Missing Freebox permission.*freebox_dhcp_lease needs the "settings" permission`)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, lease("192.168.1.42")+others),
+			},
+			{
+				PreConfig:   revoke,
+				Config:      testConfig(srv, lease("192.168.1.43")+others),
+				PlanOnly:    true,
+				ExpectError: missing,
+			},
+			{
+				// Unchanged: refreshing needs no permission.
+				Config:   testConfig(srv, lease("192.168.1.42")+others),
+				PlanOnly: true,
+			},
+			{
+				// Deleting the lease writes to the box.
+				Config:      testConfig(srv, others),
+				PlanOnly:    true,
+				ExpectError: missing,
+			},
+			{
+				// The others leave the box as is on destroy.
+				Config: testConfig(srv, lease("192.168.1.42")),
+				Check: checkServer(func() error {
+					if c := srv.DhcpConfig(); c.IPRangeEnd != "192.168.1.60" {
+						return fmt.Errorf("DHCP range end on the box is %s", c.IPRangeEnd)
+					}
+					return nil
+				}),
+			},
+			{
+				PreConfig: func() { srv.SetPermission("settings", true) },
+				Config:    testConfig(srv, lease("192.168.1.42")),
+			},
+		},
+	})
+}
+
+// restore_on_destroy other than "none" writes on destroy, so it needs the
+// permission.
+func TestAccPermissionSettingsRestore(t *testing.T) {
+	srv := newTestServer(t)
+	config := func(srv *fbxtest.Server, restore string) string {
+		return testConfig(srv, fmt.Sprintf("resource \"freebox_dhcp_config\" \"test\" {\n  ip_range_end       = \"192.168.1.60\"\n  restore_on_destroy = %q\n}\n", restore))
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(srv, restoreOriginal),
+			},
+			{
+				PreConfig:   func() { srv.SetPermission("settings", false) },
+				Config:      srv.ProviderConfig(),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`(?s)Missing Freebox permission.*freebox_dhcp_config needs`),
+			},
+			{
+				PreConfig: func() { srv.SetPermission("settings", true) },
+				Config:    srv.ProviderConfig(),
+				Check:     checkDhcpRange(srv, "192.168.1.1", "192.168.1.50"),
+			},
+		},
+	})
+}
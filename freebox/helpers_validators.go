@@ -0,0 +1,188 @@
+package freebox
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ipValidator checks a string is an IP address; v4Only rejects IPv6.
+type ipValidator struct{ v4Only bool }
+
+func (v ipValidator) Description(context.Context) string {
+	if v.v4Only {
+		return "value must be an IPv4 address"
+	}
+	return "value must be an IPv4 or IPv6 address"
+}
+
+func (v ipValidator) MarkdownDescription(ctx context.Context) string { return v.Description(ctx) }
+
+func (v ipValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if msg := checkIP(req.ConfigValue.ValueString(), v.v4Only); msg != "" {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid IP address", msg)
+	}
+}
+
+func checkIP(s string, v4Only bool) string {
+	ip := net.ParseIP(s)
+	switch {
+	case ip == nil:
+		return fmt.Sprintf("%q is not an IP address.", s)
+	case v4Only && ip.To4() == nil:
+		return fmt.Sprintf("%q is not an IPv4 address; the Freebox only accepts IPv4 here.", s)
+	}
+	return ""
+}
+
+// ipListValidator checks every element of a list of IP addresses. Empty
+// entries are allowed, as the Freebox uses them for unused slots.
+type ipListValidator struct{}
+
+func (ipListValidator) Description(context.Context) string {
+	return "each element must be an IPv4 or IPv6 address, or empty"
+}
+
+func (v ipListValidator) MarkdownDescription(ctx context.Context) string { return v.Description(ctx) }
+
+func (ipListValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	var elems []types.String
+	resp.Diagnostics.Append(req.ConfigValue.ElementsAs(ctx, &elems, false)...)
+	for i, e := range elems {
+		if e.IsNull() || e.IsUnknown() || e.ValueString() == "" {
+			continue
+		}
+		if msg := checkIP(e.ValueString(), false); msg != "" {
+			resp.Diagnostics.AddAttributeError(req.Path.AtListIndex(i), "Invalid IP address", msg)
+		}
+	}
+}
+
+// macValidator checks a string is a 48-bit MAC address, in any of the forms
+// api.NormalizeMAC accepts.
+type macValidator struct{}
+
+func (macValidator) Description(context.Context) string {
+	return "value must be a MAC address such as AA:BB:CC:DD:EE:FF"
+}
+
+func (v macValidator) MarkdownDescription(ctx context.Context) string { return v.Description(ctx) }
+
+func (macValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if s := req.ConfigValue.ValueString(); api.NormalizeMAC(s) == "" {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid MAC address",
+			fmt.Sprintf("%q is not a MAC address; expected six hex octets such as AA:BB:CC:DD:EE:FF.", s))
+	}
+}
+
+// oneOfValidator checks a string is one of values.
+type oneOfValidator struct{ values []string }
+
+func (v oneOfValidator) Description(context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.values, ", "))
+}
+
+func (v oneOfValidator) MarkdownDescription(ctx context.Context) string { return v.Description(ctx) }
+
+func (v oneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	s := req.ConfigValue.ValueString()
+	for _, ok := range v.values {
+		if s == ok {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(req.Path, "Invalid value",
+		fmt.Sprintf("%q is not valid: %s.", s, v.Description(ctx)))
+}
+
+// portValidator checks a number is a TCP/UDP port (1-65535).
+type portValidator struct{}
+
+func (portValidator) Description(context.Context) string {
+	return "value must be a port between 1 and 65535"
+}
+
+func (v portValidator) MarkdownDescription(ctx context.Context) string { return v.Description(ctx) }
+
+func (portValidator) ValidateInt64(_ context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if p := req.ConfigValue.ValueInt64(); p < 1 || p > 65535 {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid port",
+			fmt.Sprintf("Port %d is out of range: it must be between 1 and 65535.", p))
+	}
+}
+
+// ipLess reports whether IPv4 address a sorts before b.
+func ipLess(a, b net.IP) bool {
+	x, y := a.To4(), b.To4()
+	for i := range x {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return false
+}
+
+// lanSubnet returns the LAN network of the box, from the gateway and netmask
+// of its DHCP server, or nil when they cannot be told.
+func lanSubnet(gateway, netmask string) *net.IPNet {
+	gw := net.ParseIP(gateway).To4()
+	mask := net.ParseIP(netmask).To4()
+	if gw == nil || mask == nil {
+		return nil
+	}
+	m := net.IPMask(mask)
+	return &net.IPNet{IP: gw.Mask(m), Mask: m}
+}
+
+// fetchLANSubnet reads the LAN network from the box for plan-time checks.
+// It is best effort: on error the checks are left to the box at apply time.
+func fetchLANSubnet(ctx context.Context, c *api.Client) *net.IPNet {
+	if c == nil {
+		return nil
+	}
+	cfg, err := c.GetDhcpConfig(ctx)
+	if err != nil {
+		return nil
+	}
+	return lanSubnet(cfg.Gateway, cfg.Netmask)
+}
+
+// checkInLAN adds an error on attr when ip is a known IPv4 address outside
+// lan.
+func checkInLAN(lan *net.IPNet, attr path.Path, ip types.String, diags *diag.Diagnostics) {
+	if outsideLAN(lan, ip) {
+		diags.AddAttributeError(attr, "Address outside the LAN",
+			fmt.Sprintf("%s is not in the Freebox LAN %s.", ip.ValueString(), lan))
+	}
+}
+
+// outsideLAN reports whether ip is a known IPv4 address outside lan.
+func outsideLAN(lan *net.IPNet, ip types.String) bool {
+	if lan == nil || ip.IsNull() || ip.IsUnknown() {
+		return false
+	}
+	addr := net.ParseIP(ip.ValueString()).To4()
+	return addr != nil && !lan.Contains(addr)
+}
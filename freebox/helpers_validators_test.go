@@ -0,0 +1,140 @@
+package freebox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestIPValidator(t *testing.T) {
+	tests := []struct {
+		value   types.String
+		v4Only  bool
+		wantErr bool
+	}{
+		{types.StringValue("192.168.1.42"), true, false},
+		{types.StringValue("192.168.1.42"), false, false},
+		{types.StringValue("2001:db8::1"), false, false},
+		{types.StringValue("2001:db8::1"), true, true},
+		{types.StringValue("192.168.1.256"), false, true},
+		{types.StringValue("192.168.1"), false, true},
+		{types.StringValue(" 192.168.1.42"), false, true},
+		{types.StringValue(""), false, true},
+		{types.StringValue("nas"), true, true},
+		{types.StringNull(), true, false},
+		{types.StringUnknown(), true, false},
+	}
+	for _, tt := range tests {
+		var resp validator.StringResponse
+		ipValidator{v4Only: tt.v4Only}.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("ip"), ConfigValue: tt.value}, &resp)
+		if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+			t.Errorf("ipValidator{v4Only: %t} on %s: error = %t, want %t", tt.v4Only, tt.value, got, tt.wantErr)
+		}
+	}
+}
+
+func TestMACValidator(t *testing.T) {
+	tests := []struct {
+		value   types.String
+		wantErr bool
+	}{
+		{types.StringValue("AA:BB:CC:DD:EE:01"), false},
+		{types.StringValue("aa:bb:cc:dd:ee:01"), false},
+		{types.StringValue("aa-bb-cc-dd-ee-01"), false},
+		{types.StringValue("aabb.ccdd.ee01"), false},
+		{types.StringValue("AA:BB:CC:DD:EE"), true},
+		{types.StringValue("AA:BB:CC:DD:EE:01:02"), true},
+		{types.StringValue("GG:BB:CC:DD:EE:01"), true},
+		{types.StringValue(""), true},
+		{types.StringNull(), false},
+		{types.StringUnknown(), false},
+	}
+	for _, tt := range tests {
+		var resp validator.StringResponse
+		macValidator{}.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("mac"), ConfigValue: tt.value}, &resp)
+		if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+			t.Errorf("macValidator on %s: error = %t, want %t", tt.value, got, tt.wantErr)
+		}
+	}
+}
+
+func TestPortValidator(t *testing.T) {
+	tests := []struct {
+		value   types.Int64
+		wantErr bool
+	}{
+		{types.Int64Value(1), false},
+		{types.Int64Value(8080), false},
+		{types.Int64Value(65535), false},
+		{types.Int64Value(0), true},
+		{types.Int64Value(-1), true},
+		{types.Int64Value(65536), true},
+		{types.Int64Null(), false},
+		{types.Int64Unknown(), false},
+	}
+	for _, tt := range tests {
+		var resp validator.Int64Response
+		portValidator{}.ValidateInt64(context.Background(), validator.Int64Request{Path: path.Root("lan_port"), ConfigValue: tt.value}, &resp)
+		if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+			t.Errorf("portValidator on %s: error = %t, want %t", tt.value, got, tt.wantErr)
+		}
+	}
+}
+
+func TestDhcpConfigValidateRange(t *testing.T) {
+	ctx := context.Background()
+	r := &dhcpConfigResource{}
+	var s resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &s)
+	typ := s.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	// config sets ip_range_start and ip_range_end, nil meaning unknown, and
+	// leaves the other attributes null.
+	config := func(start, end *string) tfsdk.Config {
+		vals := map[string]tftypes.Value{}
+		for name, at := range typ.AttributeTypes {
+			vals[name] = tftypes.NewValue(at, nil)
+		}
+		for name, v := range map[string]*string{"ip_range_start": start, "ip_range_end": end} {
+			if v == nil {
+				vals[name] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+			} else if *v != "" {
+				vals[name] = tftypes.NewValue(tftypes.String, *v)
+			}
+		}
+		return tfsdk.Config{Schema: s.Schema, Raw: tftypes.NewValue(typ, vals)}
+	}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		start, end *string
+		wantErr    bool
+	}{
+		{"ordered", str("192.168.1.10"), str("192.168.1.60"), false},
+		{"single address", str("192.168.1.10"), str("192.168.1.10"), false},
+		{"reversed", str("192.168.1.60"), str("192.168.1.10"), true},
+		{"reversed across octets", str("192.168.2.1"), str("192.168.1.254"), true},
+		{"ordered across octets", str("192.168.1.254"), str("192.168.2.1"), false},
+		{"start only", str("192.168.1.60"), str(""), false},
+		{"end only", str(""), str("192.168.1.10"), false},
+		{"start unknown", nil, str("192.168.1.10"), false},
+		{"end unknown", str("192.168.1.60"), nil, false},
+		{"invalid address", str("nas"), str("192.168.1.10"), false}, // left to ipValidator
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp resource.ValidateConfigResponse
+			r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: config(tt.start, tt.end)}, &resp)
+			if got := resp.Diagnostics.HasError(); got != tt.wantErr {
+				t.Errorf("error = %t, want %t: %v", got, tt.wantErr, resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package freebox
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Oldest Freebox API major version serving each family of endpoints the
+// provider uses, as documented in the Freebox OS SDK. All of them, fields
+// included, date from the first public API.
+const (
+	apiMinDHCP        = 1 // /dhcp/config/, /dhcp/static_lease/, /dhcp/dynamic_lease/
+	apiMinPortForward = 1 // /fw/redir/
+	apiMinLanConfig   = 1 // /lan/config/
+	apiMinLanBrowser  = 1 // /lan/browser/
+)
+
+// apiVersionWarned remembers which types already warned about the box API
+// version, so the warning shows once per run rather than once per instance.
+var apiVersionWarned sync.Map
+
+// requireAPIVersion warns when the box serves an older API than typeName needs.
+func requireAPIVersion(c *api.Client, typeName string, major int, diags *diag.Diagnostics) {
+	if c == nil || c.SupportsAPI(major) {
+		return
+	}
+	if _, seen := apiVersionWarned.LoadOrStore(typeName, true); seen {
+		return
+	}
+	v := c.Version()
+	diags.AddWarning("Freebox API too old for "+typeName,
+		fmt.Sprintf("%s needs Freebox API v%d or later, but the %s serves v%s. Update Freebox OS; requests may fail until then.",
+			typeName, major, v.BoxModelName, v.APIVersion))
+}
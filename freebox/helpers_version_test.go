@@ -0,0 +1,41 @@
+package freebox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestRequireAPIVersion(t *testing.T) {
+	c := api.NewClient(api.Config{
+		BaseURL: "http://mafreebox.freebox.fr/api/v4",
+		Version: &api.APIVersion{APIVersion: "4.0", BoxModelName: "Freebox v6 (r2)"},
+	})
+
+	var diags diag.Diagnostics
+	for _, major := range []int{apiMinDHCP, apiMinPortForward, apiMinLanConfig, apiMinLanBrowser, 4} {
+		requireAPIVersion(c, "freebox_test_ok", major, &diags)
+	}
+	if len(diags) != 0 {
+		t.Fatalf("API v4 box: %v, want no diagnostics", diags)
+	}
+
+	requireAPIVersion(c, "freebox_test_new", 5, &diags)
+	if len(diags) != 1 || diags[0].Severity() != diag.SeverityWarning ||
+		!strings.Contains(diags[0].Detail(), "freebox_test_new needs Freebox API v5 or later, but the Freebox v6 (r2) serves v4.0") {
+		t.Fatalf("needs v5: %v, want one warning", diags)
+	}
+	// Once per type and run.
+	requireAPIVersion(c, "freebox_test_new", 5, &diags)
+	if len(diags) != 1 {
+		t.Errorf("second instance: %d diagnostics, want the first warning only", len(diags))
+	}
+
+	// Not discovered: nothing to compare with.
+	requireAPIVersion(api.NewClient(api.Config{BaseURL: "http://mafreebox.freebox.fr/api/v8"}), "freebox_test_pinned", 99, &diags)
+	if len(diags) != 1 {
+		t.Errorf("unknown version: %d diagnostics, want none added", len(diags))
+	}
+}
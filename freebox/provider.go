@@ -1,128 +1,40 @@
 package freebox
 
 import (
-	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha1"
-	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-const hardcodedAppID = "fr.freebox.terraform"
-
-// ---------- HTTP client & auth ----------
-
-type Client struct {
-	baseURL      string
-	appToken     string
-	http         *http.Client
-	sessionToken string
-}
-
-func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if c.sessionToken != "" {
-		req.Header.Set("X-Fbx-App-Auth", c.sessionToken)
-	}
-	return req, nil
-}
-
-func (c *Client) openSession(ctx context.Context) error {
-	// Step 1: get challenge
-	type loginResp struct {
-		Success bool `json:"success"`
-		Result  struct {
-			Challenge string `json:"challenge"`
-		} `json:"result"`
-		Msg       string `json:"msg"`
-		ErrorCode string `json:"error_code"`
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/login/", nil)
-	if err != nil {
-		return fmt.Errorf("build login request: %w", err)
-	}
-	res, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("get challenge: %w", err)
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("get challenge status %d: %s", res.StatusCode, string(b))
-	}
-
-	var l loginResp
-	if err := json.NewDecoder(res.Body).Decode(&l); err != nil {
-		return fmt.Errorf("decode challenge: %w", err)
-	}
-	if l.Result.Challenge == "" {
-		return fmt.Errorf("empty challenge from Freebox")
-	}
-
-	// Step 2: compute password = HMAC-SHA1(app_token, challenge)
-	mac := hmac.New(sha1.New, []byte(c.appToken))
-	mac.Write([]byte(l.Result.Challenge))
-	password := hex.EncodeToString(mac.Sum(nil))
-
-	// Step 3: open session
-	payload := map[string]string{
-		"app_id":   hardcodedAppID,
-		"password": password,
-	}
-	body, _ := json.Marshal(payload)
-
-	sessReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/login/session/", bytes.NewBuffer(body))
-	if err != nil {
-		return fmt.Errorf("build session request: %w", err)
-	}
-	sessReq.Header.Set("Content-Type", "application/json")
-
-	sessRes, err := c.http.Do(sessReq)
-	if err != nil {
-		return fmt.Errorf("open session: %w", err)
-	}
-	defer sessRes.Body.Close()
-
-	if sessRes.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(sessRes.Body)
-		return fmt.Errorf("open session status %d: %s", sessRes.StatusCode, string(b))
-	}
-
-	var env struct {
-		Success bool `json:"success"`
-		Result  struct {
-			SessionToken string `json:"session_token"`
-		} `json:"result"`
-		Msg       string `json:"msg"`
-		ErrorCode string `json:"error_code"`
-	}
-	if err := json.NewDecoder(sessRes.Body).Decode(&env); err != nil {
-		return fmt.Errorf("decode session: %w", err)
-	}
-	if !env.Success || env.Result.SessionToken == "" {
-		return fmt.Errorf("no session token returned (msg=%s, error_code=%s)", env.Msg, env.ErrorCode)
-	}
+const (
+	defaultAppID   = "fr.freebox.terraform"
+	defaultBaseURL = "http://mafreebox.freebox.fr"
+	defaultTimeout = 15 * time.Second
+	defaultRetries = 3
+	defaultMaxPar  = 4
+)
 
-	c.sessionToken = env.Result.SessionToken
-	return nil
-}
+// Environment variables read when the matching provider attribute is unset.
+const (
+	envAppToken = "FREEBOX_APP_TOKEN"
+	envBaseURL  = "FREEBOX_BASE_URL"
+	envAppID    = "FREEBOX_APP_ID"
+	envTimeout  = "FREEBOX_TIMEOUT"
+)
 
 // ---------- Provider ----------
 
@@ -130,6 +42,17 @@ func New() provider.Provider { return &freeboxProvider{} }
 
 type freeboxProvider struct{}
 
+type providerModel struct {
+	AppToken      types.String `tfsdk:"app_token"`
+	BaseURL       types.String `tfsdk:"base_url"`
+	AppID         types.String `tfsdk:"app_id"`
+	CACertPEM     types.String `tfsdk:"ca_cert_pem"`
+	TLSServerName types.String `tfsdk:"tls_server_name"`
+	Timeout       types.String `tfsdk:"request_timeout"`
+	MaxRetries    types.Int64  `tfsdk:"max_retries"`
+	MaxParallel   types.Int64  `tfsdk:"max_parallel_requests"`
+}
+
 func (p *freeboxProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "freebox"
 }
@@ -138,12 +61,37 @@ func (p *freeboxProvider) Schema(_ context.Context, _ provider.SchemaRequest, re
 	resp.Schema = pschema.Schema{
 		Attributes: map[string]pschema.Attribute{
 			"app_token": pschema.StringAttribute{
-				Required:    true,
-				Description: "Freebox application token (after approving the app on the Freebox).",
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Freebox application token (after approving the app on the Freebox). Can also be set with the FREEBOX_APP_TOKEN environment variable.",
 			},
 			"base_url": pschema.StringAttribute{
 				Optional:    true,
-				Description: "Freebox API base URL. Default is http://mafreebox.freebox.fr/api/v8",
+				Description: "Freebox URL. Default is http://mafreebox.freebox.fr; the API version is discovered from /api_version. Use https://mafreebox.freebox.fr on the LAN or https://<id>.fbxos.fr:<port> for remote access. A versioned URL such as http://mafreebox.freebox.fr/api/v8 pins the API version. Can also be set with the FREEBOX_BASE_URL environment variable.",
+			},
+			"app_id": pschema.StringAttribute{
+				Optional:    true,
+				Description: "Application id the app_token was issued for. Default is fr.freebox.terraform. Registering one app id per team keeps their permissions and audit entries apart on the box. Can also be set with the FREEBOX_APP_ID environment variable.",
+			},
+			"ca_cert_pem": pschema.StringAttribute{
+				Optional:    true,
+				Description: "PEM-encoded CA certificate(s) to trust for HTTPS, in addition to the system roots and the bundled Freebox ECC Root CA. Give the RSA Freebox Root CA here for boxes whose certificate it issues.",
+			},
+			"tls_server_name": pschema.StringAttribute{
+				Optional:    true,
+				Description: "Server name to verify the Freebox certificate against, e.g. mafreebox.freebox.fr when base_url uses an IP address.",
+			},
+			"request_timeout": pschema.StringAttribute{
+				Optional:    true,
+				Description: "Timeout of a single API request, as a duration (\"30s\") or a number of seconds. Default is 15s. Can also be set with the FREEBOX_TIMEOUT environment variable.",
+			},
+			"max_retries": pschema.Int64Attribute{
+				Optional:    true,
+				Description: "How many times a request is retried, with exponential backoff, when the Freebox answers busy, 429 or 5xx, or on network errors and timeouts (idempotent requests only). Default is 3; 0 disables retries.",
+			},
+			"max_parallel_requests": pschema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of API requests in flight at once, whatever Terraform's -parallelism. Default is 4.",
 			},
 		},
 	}
@@ -154,47 +102,172 @@ func (p *freeboxProvider) Resources(_ context.Context) []func() resource.Resourc
 		NewDhcpLeaseResource,
 		NewDhcpConfigResource,
 		NewPortForwardingResource,
+		NewPortForwardResource,
+		NewLanConfigResource,
+		NewLanHostResource,
 	}
 }
 
 func (p *freeboxProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewDhcpLeasesDataSource,
+		NewDhcpDynamicLeasesDataSource,
 		NewDhcpConfigDataSource,
 		NewPortForwardingsDataSource,
+		NewAPIVersionDataSource,
+		NewLanConfigDataSource,
+		NewLanHostsDataSource,
 	}
 }
 
 func (p *freeboxProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	var cfg struct {
-		AppToken string `tfsdk:"app_token"`
-		BaseURL  string `tfsdk:"base_url"`
-	}
+	var cfg providerModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if cfg.BaseURL == "" {
-		cfg.BaseURL = "http://mafreebox.freebox.fr/api/v8"
+	for _, a := range []struct {
+		name  string
+		value attr.Value
+	}{
+		{"app_token", cfg.AppToken},
+		{"base_url", cfg.BaseURL},
+		{"app_id", cfg.AppID},
+		{"ca_cert_pem", cfg.CACertPEM},
+		{"tls_server_name", cfg.TLSServerName},
+		{"request_timeout", cfg.Timeout},
+		{"max_retries", cfg.MaxRetries},
+		{"max_parallel_requests", cfg.MaxParallel},
+	} {
+		if a.value.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(path.Root(a.name), "Unknown Freebox provider "+a.name,
+				"The provider cannot create the Freebox client because "+a.name+" depends on a value that is only known after apply. "+
+					"Set it to a static value, use the matching FREEBOX_* environment variable, or apply its dependencies first with -target.")
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	c := &Client{
-		baseURL:  cfg.BaseURL,
-		appToken: cfg.AppToken,
-		http:     &http.Client{Timeout: 15 * time.Second},
+	appToken := stringOrEnv(cfg.AppToken, envAppToken)
+	if appToken == "" {
+		resp.Diagnostics.AddAttributeError(path.Root("app_token"), "Missing Freebox app token",
+			"Set app_token in the provider block or the "+envAppToken+" environment variable.")
+		return
+	}
+	baseURL := stringOrEnv(cfg.BaseURL, envBaseURL)
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	appID := stringOrEnv(cfg.AppID, envAppID)
+	if appID == "" {
+		appID = defaultAppID
+	}
+	timeout := defaultTimeout
+	if raw := stringOrEnv(cfg.Timeout, envTimeout); raw != "" {
+		d, err := parseTimeout(raw)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("request_timeout"), "Invalid request_timeout", err.Error())
+			return
+		}
+		timeout = d
+	}
+	maxRetries := int64(defaultRetries)
+	if !cfg.MaxRetries.IsNull() {
+		maxRetries = cfg.MaxRetries.ValueInt64()
+	}
+	maxParallel := int64(defaultMaxPar)
+	if !cfg.MaxParallel.IsNull() {
+		maxParallel = cfg.MaxParallel.ValueInt64()
+	}
+	if maxRetries < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_retries"), "Invalid max_retries", "max_retries must be 0 or more.")
+	}
+	if maxParallel < 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_parallel_requests"), "Invalid max_parallel_requests", "max_parallel_requests must be 1 or more.")
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	root, pinned, err := api.SplitBaseURL(baseURL)
+	if err != nil || (!strings.HasPrefix(root, "http://") && !strings.HasPrefix(root, "https://")) {
+		resp.Diagnostics.AddAttributeError(path.Root("base_url"), "Invalid base_url",
+			"base_url must be an absolute http:// or https:// URL, e.g. https://mafreebox.freebox.fr")
+		return
 	}
 
-	if err := c.openSession(ctx); err != nil {
-		resp.Diagnostics.AddError("Failed to authenticate to Freebox", err.Error())
+	tlsCfg, err := api.TLSConfig(cfg.CACertPEM.ValueString(), cfg.TLSServerName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ca_cert_pem"), "Invalid TLS configuration", err.Error())
 		return
 	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+
+	hc := &http.Client{Timeout: timeout, Transport: transport}
 
-	tflog.Debug(ctx, "Freebox client configured", map[string]any{
-		"base_url": cfg.BaseURL,
-		"app_id":   hardcodedAppID,
+	version, err := api.DiscoverAPIVersion(ctx, hc, root)
+	switch {
+	case err != nil && pinned == 0:
+		resp.Diagnostics.AddAttributeError(path.Root("base_url"), "Failed to discover Freebox API version",
+			fmt.Sprintf("GET %s/api_version: %s. Set base_url to a versioned URL such as %s/api/v8 to skip discovery.", root, err, root))
+		return
+	case err != nil:
+		tflog.Warn(ctx, "Freebox API version discovery failed, using base_url as is", map[string]any{"error": err.Error()})
+	case pinned == 0:
+		baseURL = version.BaseURL(root)
+	case pinned > version.Major():
+		resp.Diagnostics.AddAttributeWarning(path.Root("base_url"), "Freebox API version mismatch",
+			fmt.Sprintf("base_url asks for API v%d but the %s only serves up to v%s.", pinned, version.BoxModelName, version.APIVersion))
+	}
+
+	c := api.NewClient(api.Config{
+		BaseURL:             baseURL,
+		AppID:               appID,
+		AppToken:            appToken,
+		HTTPClient:          hc,
+		Version:             version,
+		MaxRetries:          int(maxRetries),
+		MaxParallelRequests: int(maxParallel),
 	})
 
+	if err := c.Login(ctx); err != nil {
+		resp.Diagnostics.AddError("Failed to authenticate to Freebox", err.Error())
+		return
+	}
+
+	logFields := map[string]any{
+		"base_url": baseURL,
+		"app_id":   appID,
+	}
+	if version != nil {
+		logFields["api_version"] = version.APIVersion
+		logFields["box_model"] = version.BoxModel
+	}
+	tflog.Debug(ctx, "Freebox client configured", logFields)
+
 	resp.ResourceData = c
 	resp.DataSourceData = c
 }
+
+// stringOrEnv returns v, or the environment variable env when v is null/empty.
+func stringOrEnv(v types.String, env string) string {
+	if s := v.ValueString(); s != "" {
+		return s
+	}
+	return os.Getenv(env)
+}
+
+// parseTimeout accepts a Go duration ("30s", "2m") or a number of seconds.
+func parseTimeout(raw string) (time.Duration, error) {
+	s := raw
+	if n, err := strconv.Atoi(raw); err == nil {
+		s = strconv.Itoa(n) + "s"
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q is not a positive duration such as 30s or 30", raw)
+	}
+	return d, nil
+}
@@ -0,0 +1,234 @@
+package freebox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Acceptance tests run Terraform against fbxtest, a fake Freebox, so they need
+// a terraform binary but no box: TF_ACC=1 go test ./...
+
+var testProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"freebox": providerserver.NewProtocol6WithError(New()),
+}
+
+// newTestServer starts a fake Freebox closed at the end of the test.
+func newTestServer(t *testing.T) *fbxtest.Server {
+	t.Helper()
+	srv := fbxtest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testConfig prepends the provider block pointing at srv to body.
+func testConfig(srv *fbxtest.Server, body string) string {
+	return srv.ProviderConfig() + body
+}
+
+// checkServer turns an assertion on the fake's state into a TestCheckFunc.
+func checkServer(f func() error) func(*terraform.State) error {
+	return func(*terraform.State) error { return f() }
+}
+
+// testLanHostAt adds a LAN browser host named name, seen at ip, to srv.
+func testLanHostAt(srv *fbxtest.Server, mac, name, ip string) {
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{
+		PrimaryName:      name,
+		L2Ident:          api.LanHostL2Ident{ID: mac},
+		L3Connectivities: []api.LanHostL3Connectivity{{Addr: ip, Af: "ipv4", Active: true}},
+	})
+}
+
+// failTransient makes the next calls expire the session once, then answer
+// busy twice: the client must renew the session and retry for the step to
+// pass.
+func failTransient(srv *fbxtest.Server) {
+	srv.FailNext(1, 403, "auth_required")
+	srv.FailNext(2, 503, "busy")
+}
+
+// configureProvider runs the provider Configure with attrs set and the other
+// attributes null, and returns the client it built, if any.
+func configureProvider(t *testing.T, attrs map[string]tftypes.Value) (*api.Client, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+	p := New()
+	var s provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &s)
+	typ := s.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	vals := map[string]tftypes.Value{}
+	for name, at := range typ.AttributeTypes {
+		vals[name] = tftypes.NewValue(at, nil)
+	}
+	for name, v := range attrs {
+		vals[name] = v
+	}
+
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{Config: tfsdk.Config{Schema: s.Schema, Raw: tftypes.NewValue(typ, vals)}}, &resp)
+	c, _ := resp.ResourceData.(*api.Client)
+	return c, resp.Diagnostics
+}
+
+// unsetProviderEnv clears the provider environment variables for the test.
+func unsetProviderEnv(t *testing.T) {
+	for _, env := range []string{envAppToken, envBaseURL, envAppID, envTimeout} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestProviderConfigureEnv(t *testing.T) {
+	str := func(s string) tftypes.Value { return tftypes.NewValue(tftypes.String, s) }
+
+	t.Run("from the environment", func(t *testing.T) {
+		srv := newTestServer(t)
+		unsetProviderEnv(t)
+		t.Setenv(envBaseURL, srv.URL)
+		t.Setenv(envAppToken, srv.AppToken)
+		t.Setenv(envAppID, srv.AppID)
+		t.Setenv(envTimeout, "30")
+
+		c, diags := configureProvider(t, nil)
+		if diags.HasError() {
+			t.Fatalf("Configure: %v", diags)
+		}
+		if c.BaseURL() != srv.BaseURL() || c.AppID() != srv.AppID {
+			t.Errorf("client for %s as %s, want %s as %s", c.BaseURL(), c.AppID(), srv.BaseURL(), srv.AppID)
+		}
+	})
+
+	t.Run("attributes win", func(t *testing.T) {
+		srv := newTestServer(t)
+		t.Setenv(envBaseURL, "http://127.0.0.1:1")
+		t.Setenv(envAppToken, "wrong")
+		t.Setenv(envAppID, "fr.freebox.other")
+		t.Setenv(envTimeout, "abc")
+
+		c, diags := configureProvider(t, map[string]tftypes.Value{
+			"base_url": str(srv.URL), "app_token": str(srv.AppToken), "app_id": str(srv.AppID), "request_timeout": str("10s"),
+		})
+		if diags.HasError() {
+			t.Fatalf("Configure: %v", diags)
+		}
+		if c.BaseURL() != srv.BaseURL() {
+			t.Errorf("client for %s, want %s", c.BaseURL(), srv.BaseURL())
+		}
+	})
+
+	t.Run("app id from the environment", func(t *testing.T) {
+		srv := newTestServer(t)
+		unsetProviderEnv(t)
+		t.Setenv(envAppID, "fr.freebox.other")
+
+		_, diags := configureProvider(t, map[string]tftypes.Value{"base_url": str(srv.URL), "app_token": str(srv.AppToken)})
+		if !hasDiag(diags, "Failed to authenticate to Freebox") {
+			t.Errorf("Configure: %v, want the token refused for fr.freebox.other", diags)
+		}
+	})
+
+	t.Run("timeout from the environment", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { time.Sleep(500 * time.Millisecond) }))
+		t.Cleanup(slow.Close)
+		unsetProviderEnv(t)
+		t.Setenv(envTimeout, "50ms")
+
+		_, diags := configureProvider(t, map[string]tftypes.Value{"base_url": str(slow.URL), "app_token": str("token")})
+		if !hasDiag(diags, "Failed to discover Freebox API version") {
+			t.Errorf("Configure: %v, want discovery to time out", diags)
+		}
+	})
+
+	for _, tt := range []struct{ env, value, want string }{
+		{envTimeout, "abc", "Invalid request_timeout"},
+		{envTimeout, "0", "Invalid request_timeout"},
+		{envAppToken, "", "Missing Freebox app token"},
+	} {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			srv := newTestServer(t)
+			unsetProviderEnv(t)
+			t.Setenv(envBaseURL, srv.URL)
+			t.Setenv(envAppToken, srv.AppToken)
+			t.Setenv(tt.env, tt.value)
+
+			if _, diags := configureProvider(t, nil); !hasDiag(diags, tt.want) {
+				t.Errorf("Configure: %v, want %q", diags, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureUnknown(t *testing.T) {
+	unsetProviderEnv(t)
+	for name, typ := range map[string]tftypes.Type{
+		"app_token":             tftypes.String,
+		"base_url":              tftypes.String,
+		"app_id":                tftypes.String,
+		"ca_cert_pem":           tftypes.String,
+		"tls_server_name":       tftypes.String,
+		"request_timeout":       tftypes.String,
+		"max_retries":           tftypes.Number,
+		"max_parallel_requests": tftypes.Number,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Set in the environment as well: an unknown value is not null.
+			t.Setenv(envAppToken, "token")
+			_, diags := configureProvider(t, map[string]tftypes.Value{name: tftypes.NewValue(typ, tftypes.UnknownValue)})
+			if !hasDiag(diags, "Unknown Freebox provider "+name) || diags.ErrorsCount() != 1 {
+				t.Errorf("Configure: %v, want one unknown %s error", diags, name)
+			}
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "30", want: 30 * time.Second},
+		{raw: "30s", want: 30 * time.Second},
+		{raw: "2m", want: 2 * time.Minute},
+		{raw: "1500ms", want: 1500 * time.Millisecond},
+		{raw: "0", wantErr: true},
+		{raw: "0s", wantErr: true},
+		{raw: "-5", wantErr: true},
+		{raw: "abc", wantErr: true},
+		{raw: "30 s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeout(tt.raw)
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("parseTimeout(%q) = %s, want an error", tt.raw, got)
+		case tt.wantErr && !strings.Contains(err.Error(), strconv.Quote(tt.raw)):
+			t.Errorf("parseTimeout(%q) error %q does not quote the value", tt.raw, err)
+		case !tt.wantErr && (err != nil || got != tt.want):
+			t.Errorf("parseTimeout(%q) = %s, %v, want %s", tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func hasDiag(diags diag.Diagnostics, summary string) bool {
+	for _, d := range diags {
+		if d.Summary() == summary {
+			return true
+		}
+	}
+	return false
+}
@@ -2,64 +2,73 @@
 package freebox
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net"
+	"slices"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource                = &dhcpConfigResource{}
-	_ resource.ResourceWithConfigure   = &dhcpConfigResource{}
-	_ resource.ResourceWithImportState = &dhcpConfigResource{}
+	_ resource.Resource                   = &dhcpConfigResource{}
+	_ resource.ResourceWithConfigure      = &dhcpConfigResource{}
+	_ resource.ResourceWithImportState    = &dhcpConfigResource{}
+	_ resource.ResourceWithModifyPlan     = &dhcpConfigResource{}
+	_ resource.ResourceWithValidateConfig = &dhcpConfigResource{}
 )
 
 func NewDhcpConfigResource() resource.Resource { return &dhcpConfigResource{} }
 
-type dhcpConfigResource struct{ client *Client }
+type dhcpConfigResource struct{ client *api.Client }
 
-type apiDhcpConfig struct {
-	Enabled              bool     `json:"enabled"`
-	StickyAssign         bool     `json:"sticky_assign"`
-	Gateway              string   `json:"gateway"` // read-only
-	Netmask              string   `json:"netmask"` // read-only
-	IPRangeStart         string   `json:"ip_range_start"`
-	IPRangeEnd           string   `json:"ip_range_end"`
-	AlwaysBroadcast      bool     `json:"always_broadcast"`
-	IgnoreOutOfRangeHint bool     `json:"ignore_out_of_range_hint"`
-	DNS                  []string `json:"dns"`
+type dhcpConfigModel struct {
+	Id                   types.String `tfsdk:"id"`
+	Enabled              types.Bool   `tfsdk:"enabled"`
+	StickyAssign         types.Bool   `tfsdk:"sticky_assign"`
+	Gateway              types.String `tfsdk:"gateway"`
+	Netmask              types.String `tfsdk:"netmask"`
+	IpRangeStart         types.String `tfsdk:"ip_range_start"`
+	IpRangeEnd           types.String `tfsdk:"ip_range_end"`
+	AlwaysBroadcast      types.Bool   `tfsdk:"always_broadcast"`
+	IgnoreOutOfRangeHint types.Bool   `tfsdk:"ignore_out_of_range_hint"`
+	Dns                  types.List   `tfsdk:"dns"`
+	RestoreOnDestroy     types.String `tfsdk:"restore_on_destroy"`
 }
 
-type envCfg[T any] struct {
-	Success   bool   `json:"success"`
-	Result    T      `json:"result"`
-	Msg       string `json:"msg"`
-	ErrorCode string `json:"error_code"`
-}
+// restore_on_destroy modes.
+const (
+	restoreNone     = "none"
+	restoreOriginal = "original"
+	restoreDefaults = "defaults"
+)
 
-type dhcpConfigModel struct {
-	Id                   types.String   `tfsdk:"id"`
-	Enabled              types.Bool     `tfsdk:"enabled"`
-	StickyAssign         types.Bool     `tfsdk:"sticky_assign"`
-	Gateway              types.String   `tfsdk:"gateway"`
-	Netmask              types.String   `tfsdk:"netmask"`
-	IpRangeStart         types.String   `tfsdk:"ip_range_start"`
-	IpRangeEnd           types.String   `tfsdk:"ip_range_end"`
-	AlwaysBroadcast      types.Bool     `tfsdk:"always_broadcast"`
-	IgnoreOutOfRangeHint types.Bool     `tfsdk:"ignore_out_of_range_hint"`
-	Dns                  []types.String `tfsdk:"dns"`
+// privateOriginalConfig is the private state key holding the configuration
+// found on the box before the first apply, as JSON.
+const privateOriginalConfig = "original_config"
+
+// dhcpConfigErrors scopes DHCP error codes to the attribute they concern.
+var dhcpConfigErrors = errorScope{
+	codes: map[string]string{
+		"inval_ip_range":     "ip_range_start",
+		"inval_ip_range_net": "ip_range_start",
+	},
+	attrs: []string{"enabled", "sticky_assign", "ip_range_start", "ip_range_end", "always_broadcast", "ignore_out_of_range_hint", "dns"},
 }
 
 func (r *dhcpConfigResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -72,14 +81,25 @@ func (r *dhcpConfigResource) Schema(_ context.Context, _ resource.SchemaRequest,
 		Attributes: map[string]rschema.Attribute{
 			"id": rschema.StringAttribute{Computed: true, Description: "Synthetic ID.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
 
-			// Writable
-			"enabled":                  rschema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(false), Description: "Enable/Disable DHCP server."},
-			"sticky_assign":            rschema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Always assign same IP to a host."},
-			"ip_range_start":           rschema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString(""), Description: "DHCP range start IP."},
-			"ip_range_end":             rschema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString(""), Description: "DHCP range end IP."},
-			"always_broadcast":         rschema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(false), Description: "Always broadcast DHCP responses."},
-			"ignore_out_of_range_hint": rschema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(false), Description: "Ignore requested address if outside DHCP range."},
-			"dns":                      rschema.ListAttribute{Optional: true, Computed: true, ElementType: types.StringType, Description: "DNS servers to include in replies (Freebox returns 5 items)."},
+			// Writable. Only the attributes set in the configuration are sent;
+			// the others keep whatever the box has.
+			"enabled":                  rschema.BoolAttribute{Optional: true, Computed: true, Description: "Enable/Disable DHCP server.", PlanModifiers: keepBool},
+			"sticky_assign":            rschema.BoolAttribute{Optional: true, Computed: true, Description: "Always assign same IP to a host.", PlanModifiers: keepBool},
+			"ip_range_start":           rschema.StringAttribute{Optional: true, Computed: true, Description: "DHCP range start IP.", Validators: []validator.String{ipValidator{v4Only: true}}, PlanModifiers: keepString},
+			"ip_range_end":             rschema.StringAttribute{Optional: true, Computed: true, Description: "DHCP range end IP.", Validators: []validator.String{ipValidator{v4Only: true}}, PlanModifiers: keepString},
+			"always_broadcast":         rschema.BoolAttribute{Optional: true, Computed: true, Description: "Always broadcast DHCP responses.", PlanModifiers: keepBool},
+			"ignore_out_of_range_hint": rschema.BoolAttribute{Optional: true, Computed: true, Description: "Ignore requested address if outside DHCP range.", PlanModifiers: keepBool},
+			"dns":                      rschema.ListAttribute{Optional: true, Computed: true, ElementType: types.StringType, Description: "DNS servers to include in replies (Freebox returns 5 items).", Validators: []validator.List{ipListValidator{}}, PlanModifiers: []planmodifier.List{listplanmodifier.UseStateForUnknown()}},
+
+			"restore_on_destroy": rschema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(restoreNone),
+				Description: `What destroy does to the box: "none" (default) leaves the configuration as is, ` +
+					`"original" puts back the configuration found before the first apply, ` +
+					`"defaults" applies the Freebox factory DHCP settings for the current LAN.`,
+				Validators: []validator.String{oneOfValidator{values: []string{restoreNone, restoreOriginal, restoreDefaults}}},
+			},
 
 			// Read-only
 			"gateway": rschema.StringAttribute{Computed: true, Description: "Gateway IP (read-only).", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
@@ -88,9 +108,76 @@ func (r *dhcpConfigResource) Schema(_ context.Context, _ resource.SchemaRequest,
 	}
 }
 
-func (r *dhcpConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+func (r *dhcpConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData != nil {
-		r.client = req.ProviderData.(*Client)
+		r.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(r.client, "freebox_dhcp_config", apiMinDHCP, &resp.Diagnostics)
+	}
+}
+
+func (r *dhcpConfigResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var start, end types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ip_range_start"), &start)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ip_range_end"), &end)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	from := net.ParseIP(start.ValueString()).To4()
+	to := net.ParseIP(end.ValueString()).To4()
+	if from != nil && to != nil && ipLess(to, from) {
+		resp.Diagnostics.AddAttributeError(path.Root("ip_range_end"), "Invalid DHCP range",
+			fmt.Sprintf("ip_range_end (%s) is lower than ip_range_start (%s).", to, from))
+	}
+}
+
+func (r *dhcpConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Delete only writes to the box when it restores a configuration.
+	var restore types.String
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("restore_on_destroy"), &restore)...)
+	}
+	checkPermission(r.client, permSettings, "freebox_dhcp_config", restore.ValueString() != restoreNone, req, &resp.Diagnostics)
+	if req.Plan.Raw.IsNull() || req.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	// The range must sit in the LAN defined by gateway and netmask.
+	var gateway, netmask, start, end types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("gateway"), &gateway)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("netmask"), &netmask)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("ip_range_start"), &start)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("ip_range_end"), &end)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	lan := lanSubnet(gateway.ValueString(), netmask.ValueString())
+	if lan == nil {
+		// Not created yet: check against the LAN the box has now.
+		lan = fetchLANSubnet(ctx, r.client)
+		checkInLAN(lan, path.Root("ip_range_start"), start, &resp.Diagnostics)
+		checkInLAN(lan, path.Root("ip_range_end"), end, &resp.Diagnostics)
+		return
+	}
+
+	// gateway and netmask come from state. freebox_lan_config may move the
+	// LAN in this same run, so a range outside them only warns, and the
+	// gateway and netmask are left to the apply.
+	moved := false
+	for _, a := range []struct {
+		attr path.Path
+		ip   types.String
+	}{{path.Root("ip_range_start"), start}, {path.Root("ip_range_end"), end}} {
+		if outsideLAN(lan, a.ip) {
+			moved = true
+			resp.Diagnostics.AddAttributeWarning(a.attr, "Address outside the current LAN",
+				fmt.Sprintf("%s is not in the Freebox LAN %s as last read. This is expected when freebox_lan_config moves "+
+					"the LAN in the same run: make this resource depend on it so the LAN moves first. Otherwise the "+
+					"Freebox refuses the range.", a.ip.ValueString(), lan))
+		}
+	}
+	if moved {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("gateway"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("netmask"), types.StringUnknown())...)
 	}
 }
 
@@ -105,58 +192,56 @@ func (r *dhcpConfigResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	payload := modelToPayload(plan)
-	b, _ := json.Marshal(payload)
-	hreq, _ := r.client.newRequest(ctx, http.MethodPut, "/dhcp/config/", bytes.NewBuffer(b))
-	hres, err := r.client.http.Do(hreq)
+	// Keep what was there before, for restore_on_destroy = "original".
+	original, err := r.client.GetDhcpConfig(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		dhcpConfigErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
-	defer hres.Body.Close()
+	raw, err := json.Marshal(original)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to record the original DHCP config", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateOriginalConfig, raw)...)
 
-	var env envCfg[apiDhcpConfig]
-	_ = json.NewDecoder(hres.Body).Decode(&env)
-	if hres.StatusCode != http.StatusOK || !env.Success {
-		resp.Diagnostics.AddError("API error", dhcpErrDetail(hres.StatusCode, env))
+	cfg, err := r.apply(ctx, req.Config, original, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err != nil {
+		dhcpConfigErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
 
-	state := cfgToModel(env.Result)
+	state := cfgToModel(*cfg, plan.Dns)
 	state.Id = types.StringValue("dhcp_config")
+	state.RestoreOnDestroy = plan.RestoreOnDestroy
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 	tflog.Info(ctx, "Applied DHCP config (create)")
 }
 
-func (r *dhcpConfigResource) Read(ctx context.Context, _ resource.ReadRequest, resp *resource.ReadResponse) {
+func (r *dhcpConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	if r.client == nil {
 		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
 		return
 	}
-	hreq, _ := r.client.newRequest(ctx, http.MethodGet, "/dhcp/config/", nil)
-	hres, err := r.client.http.Do(hreq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer hres.Body.Close()
-	if hres.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(hres.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", hres.StatusCode, string(b)))
-		return
+	var restore types.String
+	var dns types.List
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("restore_on_destroy"), &restore)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("dns"), &dns)...)
+	if restore.IsNull() {
+		restore = types.StringValue(restoreNone) // imported
 	}
 
-	var env envCfg[apiDhcpConfig]
-	if err := json.NewDecoder(hres.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
-		return
-	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", env.Msg)
+	cfg, err := r.client.GetDhcpConfig(ctx)
+	if err != nil {
+		dhcpConfigErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
-	state := cfgToModel(env.Result)
+	state := cfgToModel(*cfg, dns)
 	state.Id = types.StringValue("dhcp_config")
+	state.RestoreOnDestroy = restore
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
@@ -171,29 +256,73 @@ func (r *dhcpConfigResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	payload := modelToPayload(plan)
-	b, _ := json.Marshal(payload)
-	preq, _ := r.client.newRequest(ctx, http.MethodPut, "/dhcp/config/", bytes.NewBuffer(b))
-	pres, err := r.client.http.Do(preq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	cfg, err := r.apply(ctx, req.Config, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer pres.Body.Close()
-
-	var env envCfg[apiDhcpConfig]
-	_ = json.NewDecoder(pres.Body).Decode(&env)
-	if pres.StatusCode != http.StatusOK || !env.Success {
-		resp.Diagnostics.AddError("API error", dhcpErrDetail(pres.StatusCode, env))
+	if err != nil {
+		dhcpConfigErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
-	state := cfgToModel(env.Result)
+	state := cfgToModel(*cfg, plan.Dns)
 	state.Id = types.StringValue("dhcp_config")
+	state.RestoreOnDestroy = plan.RestoreOnDestroy
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 	tflog.Info(ctx, "Applied DHCP config (update)")
 }
 
-func (r *dhcpConfigResource) Delete(context.Context, resource.DeleteRequest, *resource.DeleteResponse) {
+// Delete leaves the box alone unless restore_on_destroy asks to put back the
+// original configuration or the factory defaults.
+func (r *dhcpConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	var restore types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("restore_on_destroy"), &restore)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var target *api.DhcpConfig
+	switch restore.ValueString() {
+	case restoreOriginal:
+		raw, diags := req.Private.GetKey(ctx, privateOriginalConfig)
+		resp.Diagnostics.Append(diags...)
+		if len(raw) > 0 {
+			target = &api.DhcpConfig{}
+			if err := json.Unmarshal(raw, target); err != nil {
+				resp.Diagnostics.AddError("Unable to read the original DHCP config", err.Error())
+				return
+			}
+			break
+		}
+		resp.Diagnostics.AddWarning("Original DHCP config unknown",
+			"The configuration found before the first apply was not recorded (the resource was imported, "+
+				"or created by an older provider version). The Freebox factory defaults are applied instead.")
+		fallthrough
+	case restoreDefaults:
+		current, err := r.client.GetDhcpConfig(ctx)
+		if err != nil {
+			dhcpConfigErrors.addError(&resp.Diagnostics, "API error", err)
+			return
+		}
+		target = factoryDhcpConfig(current.Gateway, current.Netmask)
+		if target == nil {
+			resp.Diagnostics.AddError("Unable to restore the DHCP defaults",
+				fmt.Sprintf("The LAN gateway %q and netmask %q reported by the Freebox leave no room for a DHCP range.", current.Gateway, current.Netmask))
+			return
+		}
+	default:
+		tflog.Info(ctx, "Leaving DHCP config in place (restore_on_destroy = none)")
+		return
+	}
+
+	if _, err := r.client.UpdateDhcpConfig(ctx, fullDhcpUpdate(*target)); err != nil {
+		dhcpConfigErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	tflog.Info(ctx, "Restored DHCP config", map[string]any{"mode": restore.ValueString()})
 }
 
 func (r *dhcpConfigResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -201,19 +330,120 @@ func (r *dhcpConfigResource) ImportState(ctx context.Context, req resource.Impor
 }
 
 // helpers
-func modelToPayload(m dhcpConfigModel) apiDhcpConfig {
-	return apiDhcpConfig{
-		Enabled:              m.Enabled.ValueBool(),
-		StickyAssign:         m.StickyAssign.ValueBool(),
-		IPRangeStart:         m.IpRangeStart.ValueString(),
-		IPRangeEnd:           m.IpRangeEnd.ValueString(),
-		AlwaysBroadcast:      m.AlwaysBroadcast.ValueBool(),
-		IgnoreOutOfRangeHint: m.IgnoreOutOfRangeHint.ValueBool(),
-		DNS:                  expandStringList(m.Dns),
+
+// factoryDhcpConfig returns the DHCP settings of a Freebox out of the box for
+// the LAN of gateway/netmask: server on, sticky assignment, the first 50
+// addresses of the LAN as range and the gateway as DNS. It returns nil when
+// the netmask is not a prefix, or leaves no room for a range (/31, /32).
+func factoryDhcpConfig(gateway, netmask string) *api.DhcpConfig {
+	lan := lanSubnet(gateway, netmask)
+	if lan == nil {
+		return nil
+	}
+	if ones, bits := lan.Mask.Size(); bits == 0 || ones > 30 {
+		return nil
+	}
+	start := make(net.IP, 4)
+	copy(start, lan.IP.To4())
+	start[3]++
+	end := make(net.IP, 4)
+	copy(end, start)
+	end[3] += 49
+	if !lan.Contains(end) {
+		// Smaller than a /26: use the whole LAN but the network and
+		// broadcast addresses.
+		ones, _ := lan.Mask.Size()
+		end = make(net.IP, 4)
+		copy(end, lan.IP.To4())
+		end[3] += byte(1<<(32-ones)) - 2
 	}
+	return &api.DhcpConfig{
+		Enabled:      true,
+		StickyAssign: true,
+		IPRangeStart: start.String(),
+		IPRangeEnd:   end.String(),
+		DNS:          []string{gateway},
+	}
+}
+
+// apply sends the attributes set in config, and only those, so settings left
+// out of the configuration keep their value on the box. current is the box
+// configuration when already known, returned as is when nothing is set.
+func (r *dhcpConfigResource) apply(ctx context.Context, config tfsdk.Config, current *api.DhcpConfig, diags *diag.Diagnostics) (*api.DhcpConfig, error) {
+	var m dhcpConfigModel
+	diags.Append(config.Get(ctx, &m)...)
+	if diags.HasError() {
+		return nil, nil
+	}
+
+	var u api.DhcpConfigUpdate
+	set := false
+	for _, f := range []struct {
+		v   types.Bool
+		dst **bool
+	}{
+		{m.Enabled, &u.Enabled},
+		{m.StickyAssign, &u.StickyAssign},
+		{m.AlwaysBroadcast, &u.AlwaysBroadcast},
+		{m.IgnoreOutOfRangeHint, &u.IgnoreOutOfRangeHint},
+	} {
+		if !f.v.IsNull() && !f.v.IsUnknown() {
+			*f.dst = f.v.ValueBoolPointer()
+			set = true
+		}
+	}
+	for _, f := range []struct {
+		v   types.String
+		dst **string
+	}{
+		{m.IpRangeStart, &u.IPRangeStart},
+		{m.IpRangeEnd, &u.IPRangeEnd},
+	} {
+		if !f.v.IsNull() && !f.v.IsUnknown() {
+			*f.dst = f.v.ValueStringPointer()
+			set = true
+		}
+	}
+	if !m.Dns.IsNull() && !m.Dns.IsUnknown() {
+		var dns []types.String
+		diags.Append(m.Dns.ElementsAs(ctx, &dns, false)...)
+		list := expandStringList(dns)
+		if list == nil {
+			list = []string{}
+		}
+		u.DNS = &list
+		set = true
+	}
+
+	if !set {
+		if current != nil {
+			return current, nil
+		}
+		return r.client.GetDhcpConfig(ctx)
+	}
+	return r.client.UpdateDhcpConfig(ctx, u)
 }
 
-func cfgToModel(c apiDhcpConfig) dhcpConfigModel {
+// fullDhcpUpdate sets every writable setting of c.
+func fullDhcpUpdate(c api.DhcpConfig) api.DhcpConfigUpdate {
+	dns := c.DNS
+	if dns == nil {
+		dns = []string{}
+	}
+	return api.DhcpConfigUpdate{
+		Enabled:              &c.Enabled,
+		StickyAssign:         &c.StickyAssign,
+		IPRangeStart:         &c.IPRangeStart,
+		IPRangeEnd:           &c.IPRangeEnd,
+		AlwaysBroadcast:      &c.AlwaysBroadcast,
+		IgnoreOutOfRangeHint: &c.IgnoreOutOfRangeHint,
+		DNS:                  &dns,
+	}
+}
+
+// cfgToModel converts the box configuration to state. prior is the dns value
+// from the plan or state, whose form is kept when it lists the same servers.
+func cfgToModel(c api.DhcpConfig, prior types.List) dhcpConfigModel {
 	return dhcpConfigModel{
 		Enabled:              types.BoolValue(c.Enabled),
 		StickyAssign:         types.BoolValue(c.StickyAssign),
@@ -223,29 +453,40 @@ func cfgToModel(c apiDhcpConfig) dhcpConfigModel {
 		IpRangeEnd:           stringOrNull(c.IPRangeEnd),
 		AlwaysBroadcast:      types.BoolValue(c.AlwaysBroadcast),
 		IgnoreOutOfRangeHint: types.BoolValue(c.IgnoreOutOfRangeHint),
-		Dns:                  flattenStringList(c.DNS),
+		Dns:                  dnsList(c.DNS, prior),
 	}
 }
 
-func dhcpErrDetail(status int, env any) string {
-	type slim struct{ Msg, ErrorCode string }
-	b, _ := json.Marshal(env)
-	var s slim
-	_ = json.Unmarshal(b, &s)
-	pretty := map[string]string{
-		"inval":              "invalid argument",
-		"inval_netmask":      "invalid netmask",
-		"inval_ip_range":     "invalid IP range",
-		"inval_ip_range_net": "IP range & netmask mismatch",
-		"inval_gw_net":       "gateway & netmask mismatch",
-		"exist":              "already exists",
-		"nodev":              "no such device",
-		"noent":              "no such entry",
-		"netdown":            "network is down",
-		"busy":               "device or resource busy",
+// dnsList turns the DNS servers returned by the box into the dns attribute.
+// The box always returns 5 entries, padded with empty strings: trailing
+// empty entries are dropped, and a prior value listing the same servers (with
+// or without padding) is kept as written, so neither form shows as a diff.
+func dnsList(dns []string, prior types.List) types.List {
+	servers := trimDNS(dns)
+	if !prior.IsNull() && !prior.IsUnknown() {
+		var elems []types.String
+		if diags := prior.ElementsAs(context.Background(), &elems, false); !diags.HasError() &&
+			slices.Equal(trimDNS(expandStringList(elems)), servers) {
+			return prior
+		}
+	}
+	elems := make([]attr.Value, len(servers))
+	for i, v := range servers {
+		elems[i] = types.StringValue(v)
 	}
-	if human, ok := pretty[s.ErrorCode]; ok {
-		return fmt.Sprintf("status %d: %s (error_code=%s: %s)", status, s.Msg, s.ErrorCode, human)
+	return types.ListValueMust(types.StringType, elems)
+}
+
+// trimDNS drops the empty entries at the end of dns.
+func trimDNS(dns []string) []string {
+	n := len(dns)
+	for n > 0 && dns[n-1] == "" {
+		n--
 	}
-	return fmt.Sprintf("status %d: %s (error_code=%s)", status, s.Msg, s.ErrorCode)
+	return dns[:n]
 }
+
+var (
+	keepBool   = []planmodifier.Bool{boolplanmodifier.UseStateForUnknown()}
+	keepString = []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+)
@@ -0,0 +1,288 @@
+package freebox
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func testDhcpConfigConfig(srv *fbxtest.Server, end string) string {
+	return testConfig(srv, fmt.Sprintf(`
+resource "freebox_dhcp_config" "test" {
+  ip_range_start     = "192.168.1.10"
+  ip_range_end       = %q
+  dns                = ["192.168.1.254", "1.1.1.1"]
+  restore_on_destroy = "original"
+}
+`, end))
+}
+
+// checkDhcpRange checks the DHCP range held by the box.
+func checkDhcpRange(srv *fbxtest.Server, start, end string) resource.TestCheckFunc {
+	return checkServer(func() error {
+		c := srv.DhcpConfig()
+		if c.IPRangeStart != start || c.IPRangeEnd != end {
+			return fmt.Errorf("DHCP range on the box is %s-%s, want %s-%s", c.IPRangeStart, c.IPRangeEnd, start, end)
+		}
+		return nil
+	})
+}
+
+func TestAccDhcpConfig(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "freebox_dhcp_config.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			// restore_on_destroy = "original" puts back the fake's defaults.
+			c := srv.DhcpConfig()
+			if c.IPRangeStart != "192.168.1.1" || c.IPRangeEnd != "192.168.1.50" {
+				return fmt.Errorf("DHCP range not restored: %s-%s", c.IPRangeStart, c.IPRangeEnd)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testDhcpConfigConfig(srv, "192.168.1.60"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "enabled", "true"),
+					resource.TestCheckResourceAttr(addr, "gateway", "192.168.1.254"),
+					resource.TestCheckResourceAttr(addr, "netmask", "255.255.255.0"),
+					resource.TestCheckResourceAttr(addr, "dns.#", "2"),
+					checkDhcpRange(srv, "192.168.1.10", "192.168.1.60"),
+					checkServer(func() error {
+						if dns := srv.DhcpConfig().DNS; !slices.Equal(dns, []string{"192.168.1.254", "1.1.1.1", "", "", ""}) {
+							return fmt.Errorf("DNS on the box is %q", dns)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:      addr,
+				ImportState:       true,
+				ImportStateId:     "dhcp_config",
+				ImportStateVerify: true,
+				// Not stored on the box: an import always reads "none".
+				ImportStateVerifyIgnore: []string{"restore_on_destroy"},
+			},
+			{
+				// Changed on the box: the plan brings it back.
+				PreConfig: func() {
+					c := srv.DhcpConfig()
+					c.IPRangeEnd = "192.168.1.70"
+					srv.SetDhcpConfig(c)
+				},
+				Config: testDhcpConfigConfig(srv, "192.168.1.60"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+				},
+				Check: checkDhcpRange(srv, "192.168.1.10", "192.168.1.60"),
+			},
+			{
+				PreConfig: func() { failTransient(srv) },
+				Config:    testDhcpConfigConfig(srv, "192.168.1.80"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "ip_range_end", "192.168.1.80"),
+					checkDhcpRange(srv, "192.168.1.10", "192.168.1.80"),
+				),
+			},
+		},
+	})
+}
+
+// The box pads dns to 5 entries; a shorter list in the configuration must not
+// show as a diff, whether the box already held it or not.
+func TestAccDhcpConfigDNSPadding(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "freebox_dhcp_config.test"
+	config := func(dns string) string {
+		return testConfig(srv, fmt.Sprintf("resource \"freebox_dhcp_config\" \"test\" {\n  dns = %s\n}\n", dns))
+	}
+	noDiff := resource.ConfigPlanChecks{
+		PostApplyPostRefresh: []plancheck.PlanCheck{plancheck.ExpectEmptyPlan()},
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// Already on the box as ["192.168.1.254", "", "", "", ""].
+				Config:           config(`["192.168.1.254"]`),
+				ConfigPlanChecks: noDiff,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "dns.#", "1"),
+					resource.TestCheckResourceAttr(addr, "dns.0", "192.168.1.254"),
+				),
+			},
+			{
+				Config:           config(`["1.1.1.1"]`),
+				ConfigPlanChecks: noDiff,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "dns.#", "1"),
+					checkServer(func() error {
+						if dns := srv.DhcpConfig().DNS; !slices.Equal(dns, []string{"1.1.1.1", "", "", "", ""}) {
+							return fmt.Errorf("DNS on the box is %q", dns)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// The padded form is accepted as well.
+				Config:           config(`["1.1.1.1", "", "", "", ""]`),
+				ConfigPlanChecks: noDiff,
+				Check:            resource.TestCheckResourceAttr(addr, "dns.#", "5"),
+			},
+			{
+				// Only dns is configured, so only dns is sent: the settings
+				// changed on the box are left alone.
+				PreConfig: func() {
+					c := srv.DhcpConfig()
+					c.StickyAssign = false
+					c.IPRangeStart, c.IPRangeEnd = "192.168.1.20", "192.168.1.30"
+					srv.SetDhcpConfig(c)
+				},
+				Config: config(`["9.9.9.9"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply:             []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+					PostApplyPostRefresh: []plancheck.PlanCheck{plancheck.ExpectEmptyPlan()},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "sticky_assign", "false"),
+					checkDhcpRange(srv, "192.168.1.20", "192.168.1.30"),
+					checkServer(func() error {
+						c := srv.DhcpConfig()
+						if !c.Enabled || c.StickyAssign {
+							return fmt.Errorf("enabled %t, sticky_assign %t on the box, want true, false", c.Enabled, c.StickyAssign)
+						}
+						if !slices.Equal(c.DNS, []string{"9.9.9.9", "", "", "", ""}) {
+							return fmt.Errorf("DNS on the box is %q", c.DNS)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestFactoryDhcpConfig(t *testing.T) {
+	tests := []struct {
+		gateway, netmask string
+		start, end       string // empty: no factory config
+	}{
+		{"192.168.1.254", "255.255.255.0", "192.168.1.1", "192.168.1.50"},
+		{"10.0.0.1", "255.255.0.0", "10.0.0.1", "10.0.0.50"},
+		{"192.168.1.1", "255.255.255.224", "192.168.1.1", "192.168.1.30"},
+		{"192.168.1.5", "255.255.255.252", "192.168.1.5", "192.168.1.6"},
+		{"192.168.1.5", "255.255.255.254", "", ""},
+		{"192.168.1.5", "255.255.255.255", "", ""},
+		{"192.168.1.254", "255.0.255.0", "", ""},
+		{"", "255.255.255.0", "", ""},
+	}
+	for _, tt := range tests {
+		got := factoryDhcpConfig(tt.gateway, tt.netmask)
+		if tt.start == "" {
+			if got != nil {
+				t.Errorf("factoryDhcpConfig(%q, %q) = %+v, want nil", tt.gateway, tt.netmask, got)
+			}
+			continue
+		}
+		if got == nil {
+			t.Errorf("factoryDhcpConfig(%q, %q) = nil, want %s-%s", tt.gateway, tt.netmask, tt.start, tt.end)
+			continue
+		}
+		if got.IPRangeStart != tt.start || got.IPRangeEnd != tt.end || !got.Enabled || !got.StickyAssign || !slices.Equal(got.DNS, []string{tt.gateway}) {
+			t.Errorf("factoryDhcpConfig(%q, %q) = %+v, want %s-%s", tt.gateway, tt.netmask, got, tt.start, tt.end)
+		}
+	}
+}
+
+func TestAccDhcpConfigRestoreDefaults(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.DhcpConfig()
+	c.StickyAssign = false
+	c.IPRangeStart, c.IPRangeEnd = "192.168.1.100", "192.168.1.120"
+	c.DNS = []string{"9.9.9.9", "", "", "", ""}
+	srv.SetDhcpConfig(c)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			// The factory settings, not the ones found at create time.
+			c := srv.DhcpConfig()
+			if !c.Enabled || !c.StickyAssign || c.IPRangeStart != "192.168.1.1" || c.IPRangeEnd != "192.168.1.50" ||
+				!slices.Equal(c.DNS, []string{"192.168.1.254", "", "", "", ""}) {
+				return fmt.Errorf("DHCP config not reset to the defaults: %+v", c)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, `
+resource "freebox_dhcp_config" "test" {
+  enabled            = false
+  ip_range_start     = "192.168.1.10"
+  ip_range_end       = "192.168.1.60"
+  restore_on_destroy = "defaults"
+}
+`),
+				Check: checkDhcpRange(srv, "192.168.1.10", "192.168.1.60"),
+			},
+		},
+	})
+}
+
+// Moving the LAN and its DHCP range in one run: the range is checked against
+// the LAN last read, so it only warns, and the gateway is left to the apply.
+func TestAccDhcpConfigReaddress(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "freebox_dhcp_config.test"
+	config := func(prefix string) string {
+		return testConfig(srv, fmt.Sprintf(`
+resource "freebox_lan_config" "test" {
+  ip = "%[1]s.254"
+}
+
+resource "freebox_dhcp_config" "test" {
+  ip_range_start = "%[1]s.10"
+  ip_range_end   = "%[1]s.80"
+  dns            = ["%[1]s.254"]
+
+  depends_on = [freebox_lan_config.test]
+}
+`, prefix))
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("192.168.1"),
+				Check:  resource.TestCheckResourceAttr(addr, "gateway", "192.168.1.254"),
+			},
+			{
+				Config: config("192.168.2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownValue(addr, tfjsonpath.New("gateway")),
+						plancheck.ExpectUnknownValue(addr, tfjsonpath.New("netmask")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "gateway", "192.168.2.254"),
+					resource.TestCheckResourceAttr(addr, "netmask", "255.255.255.0"),
+					checkDhcpRange(srv, "192.168.2.10", "192.168.2.80"),
+				),
+			},
+		},
+	})
+}
@@ -2,56 +2,55 @@
 package freebox
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource                = &dhcpLeaseResource{}
-	_ resource.ResourceWithConfigure   = &dhcpLeaseResource{}
-	_ resource.ResourceWithImportState = &dhcpLeaseResource{}
+	_ resource.Resource                 = &dhcpLeaseResource{}
+	_ resource.ResourceWithConfigure    = &dhcpLeaseResource{}
+	_ resource.ResourceWithImportState  = &dhcpLeaseResource{}
+	_ resource.ResourceWithModifyPlan   = &dhcpLeaseResource{}
+	_ resource.ResourceWithUpgradeState = &dhcpLeaseResource{}
 )
 
 func NewDhcpLeaseResource() resource.Resource { return &dhcpLeaseResource{} }
 
-type dhcpLeaseResource struct{ client *Client }
+type dhcpLeaseResource struct{ client *api.Client }
 
 type leaseModel struct {
-	Id       types.String `tfsdk:"id"`
-	Mac      types.String `tfsdk:"mac"`
-	Ip       types.String `tfsdk:"ip"`
-	Comment  types.String `tfsdk:"comment"`
-	Hostname types.String `tfsdk:"hostname"`
-	Host     types.String `tfsdk:"host"`
+	Id         types.String `tfsdk:"id"`
+	Mac        macValue     `tfsdk:"mac"`
+	Ip         types.String `tfsdk:"ip"`
+	Comment    types.String `tfsdk:"comment"`
+	Hostname   types.String `tfsdk:"hostname"`
+	Host       types.Object `tfsdk:"host"`
+	OnConflict types.String `tfsdk:"on_conflict"`
 }
 
-type apiLease struct {
-	Id       string          `json:"id"`
-	Mac      string          `json:"mac"`
-	Ip       string          `json:"ip"`
-	Comment  string          `json:"comment,omitempty"`
-	Hostname string          `json:"hostname,omitempty"`
-	Host     json.RawMessage `json:"host,omitempty"`
-}
+// on_conflict policies, applied when a lease already exists for the MAC or IP.
+const (
+	onConflictError     = "error"
+	onConflictAdopt     = "adopt"
+	onConflictOverwrite = "overwrite"
+)
 
-type apiEnvelope[T any] struct {
-	Success   bool   `json:"success"`
-	Result    T      `json:"result"`
-	Msg       string `json:"msg"`
-	ErrorCode string `json:"error_code"`
-}
+// leaseErrors scopes lease errors to the attribute named by the Freebox.
+var leaseErrors = errorScope{attrs: []string{"mac", "ip", "comment"}}
 
 func (r *dhcpLeaseResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = "freebox_dhcp_lease"
@@ -60,23 +59,85 @@ func (r *dhcpLeaseResource) Metadata(_ context.Context, _ resource.MetadataReque
 func (r *dhcpLeaseResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = rschema.Schema{
 		Description: "Manage Freebox DHCP static leases (API v8).",
+		Version:     1,
 		Attributes: map[string]rschema.Attribute{
-			"id":       rschema.StringAttribute{Computed: true, Description: "Lease id (equals MAC).", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
-			"mac":      rschema.StringAttribute{Required: true, Description: "Host MAC address.", PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()}},
-			"ip":       rschema.StringAttribute{Required: true, Description: "IPv4 to assign to the host."},
+			"id": rschema.StringAttribute{Computed: true, Description: "Lease id (equals MAC).", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
+			"mac": rschema.StringAttribute{
+				Required:      true,
+				CustomType:    macType{},
+				Description:   "Host MAC address. Case and separator (: or -) do not matter.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplaceIf(macChanged, "Replaced when the MAC address changes.", "Replaced when the MAC address changes.")},
+				Validators:    []validator.String{macValidator{}},
+			},
+			"ip":       rschema.StringAttribute{Required: true, Description: "IPv4 to assign to the host, inside the LAN.", Validators: []validator.String{ipValidator{v4Only: true}}},
 			"comment":  rschema.StringAttribute{Optional: true, Computed: true, Description: "Optional comment.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
 			"hostname": rschema.StringAttribute{Computed: true, Description: "Read-only hostname matching the MAC.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
-			"host":     rschema.StringAttribute{Computed: true, Description: "Raw JSON of LanHost (read-only).", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
+			"host": rschema.SingleNestedAttribute{
+				Computed: true,
+				Description: "LAN browser host of the MAC address, or null when the box has not seen it. Activity and addresses " +
+					"change all the time and are left out; read them from the freebox_dhcp_leases or freebox_lan_hosts data sources.",
+				Attributes: map[string]rschema.Attribute{
+					"id":           rschema.StringAttribute{Computed: true, Description: `Host ID, such as "ether-aa:bb:cc:dd:ee:ff".`},
+					"primary_name": rschema.StringAttribute{Computed: true, Description: "Host name shown in Freebox OS."},
+					"host_type":    rschema.StringAttribute{Computed: true, Description: "Device type (icon) of the host."},
+					"vendor_name":  rschema.StringAttribute{Computed: true, Description: "Vendor, from the MAC address prefix."},
+					"persistent":   rschema.BoolAttribute{Computed: true, Description: "Whether the host is kept in the LAN browser when inactive."},
+				},
+				PlanModifiers: []planmodifier.Object{objectplanmodifier.UseStateForUnknown()},
+			},
+			"on_conflict": rschema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(onConflictError),
+				Description: `What to do on create when a static lease already exists for the MAC or IP: "error" (default) fails, ` +
+					`"adopt" takes over the lease of the same MAC and applies ip and comment to it, ` +
+					`"overwrite" deletes the conflicting leases, including another device's lease on the same IP, and creates a new one.`,
+				Validators: []validator.String{oneOfValidator{values: []string{onConflictError, onConflictAdopt, onConflictOverwrite}}},
+			},
 		},
 	}
 }
 
-func (r *dhcpLeaseResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+func (r *dhcpLeaseResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData != nil {
-		r.client = req.ProviderData.(*Client)
+		r.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(r.client, "freebox_dhcp_lease", apiMinDHCP, &resp.Diagnostics)
 	}
 }
 
+func (r *dhcpLeaseResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkPermission(r.client, permSettings, "freebox_dhcp_lease", true, req, &resp.Diagnostics)
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state leaseModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !plan.Ip.IsUnknown() && !plan.Ip.Equal(state.Ip) {
+		checkInLAN(fetchLANSubnet(ctx, r.client), path.Root("ip"), plan.Ip, &resp.Diagnostics)
+	}
+
+	// hostname and host follow the LAN browser, where a freebox_lan_host of
+	// the same apply may rename the host: they are only kept from state when
+	// the lease is not written back, null included, which UseStateForUnknown
+	// leaves unknown.
+	if req.State.Raw.IsNull() {
+		return
+	}
+	hostname, host := state.Hostname, state.Host
+	if leaseWritten(plan, state) {
+		hostname, host = types.StringUnknown(), types.ObjectUnknown(leaseHostTypes)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("hostname"), hostname)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("host"), host)...)
+}
+
 func (r *dhcpLeaseResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	if r.client == nil {
 		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
@@ -88,44 +149,26 @@ func (r *dhcpLeaseResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	payload := map[string]string{"mac": plan.Mac.ValueString(), "ip": plan.Ip.ValueString()}
+	lease := api.StaticLease{Mac: plan.Mac.Normalized(), IP: plan.Ip.ValueString()}
 	if !plan.Comment.IsNull() {
-		payload["comment"] = plan.Comment.ValueString()
-	}
-
-	b, _ := json.Marshal(payload)
-	hreq, _ := r.client.newRequest(ctx, http.MethodPost, "/dhcp/static_lease/", bytes.NewBuffer(b))
-	hres, err := r.client.http.Do(hreq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
+		lease.Comment = plan.Comment.ValueString()
 	}
-	defer hres.Body.Close()
 
-	var env apiEnvelope[apiLease]
-	_ = json.NewDecoder(hres.Body).Decode(&env)
-	if hres.StatusCode != http.StatusOK && hres.StatusCode != http.StatusCreated {
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s (code=%s)", hres.StatusCode, env.Msg, env.ErrorCode))
-		return
-	}
-	if !env.Success {
-		// Attempt adopt if already exists
-		if env.ErrorCode == "already_exists" || env.ErrorCode == "exist" || env.ErrorCode == "conflict" {
-			found, diag := r.findLeaseByMacOrIP(ctx, plan.Mac.ValueString(), plan.Ip.ValueString())
-			if diag != "" {
-				resp.Diagnostics.AddError("API error (list)", diag)
-				return
-			}
-			if found != nil {
-				resp.Diagnostics.Append(resp.State.Set(ctx, toState(*found))...)
-				tflog.Info(ctx, "Adopted existing DHCP lease", map[string]any{"id": found.Id})
-				return
-			}
+	created, err := r.client.CreateStaticLease(ctx, lease)
+	switch api.ErrorCode(err) {
+	case "already_exists", "exist", "conflict":
+		created, err = r.resolveConflict(ctx, plan, lease, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("success=false: %s (code=%s)", env.Msg, env.ErrorCode))
+	}
+	if err != nil {
+		leaseErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
-	resp.Diagnostics.Append(resp.State.Set(ctx, toState(env.Result))...)
+	state := toState(*created, plan.Comment)
+	state.OnConflict = plan.OnConflict
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
 
 func (r *dhcpLeaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -141,40 +184,27 @@ func (r *dhcpLeaseResource) Read(ctx context.Context, req resource.ReadRequest,
 
 	id := state.Id.ValueString()
 	if id == "" {
-		id = state.Mac.ValueString()
+		id = state.Mac.Normalized()
 	}
 	if id == "" {
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	path := fmt.Sprintf("/dhcp/static_lease/%s", id)
-	hreq, _ := r.client.newRequest(ctx, http.MethodGet, path, nil)
-	hres, err := r.client.http.Do(hreq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer hres.Body.Close()
-	if hres.StatusCode == http.StatusNotFound {
+	lease, err := r.client.GetStaticLease(ctx, id)
+	if api.IsNotFound(err) {
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	if hres.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(hres.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", hres.StatusCode, string(b)))
-		return
-	}
-
-	var env apiEnvelope[apiLease]
-	if err := json.NewDecoder(hres.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
+	if err != nil {
+		leaseErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", env.Msg)
-		return
+	next := toState(*lease, state.Comment)
+	next.OnConflict = state.OnConflict
+	if next.OnConflict.IsNull() {
+		next.OnConflict = types.StringValue(onConflictError) // imported
 	}
-	resp.Diagnostics.Append(resp.State.Set(ctx, toState(env.Result))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, next)...)
 }
 
 func (r *dhcpLeaseResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -189,42 +219,24 @@ func (r *dhcpLeaseResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	patch := map[string]string{}
-	if !plan.Comment.IsNull() && plan.Comment.ValueString() != state.Comment.ValueString() {
-		patch["comment"] = plan.Comment.ValueString()
-	}
-	if !plan.Ip.IsNull() && plan.Ip.ValueString() != state.Ip.ValueString() {
-		patch["ip"] = plan.Ip.ValueString()
-	}
-	if len(patch) == 0 {
+	patch := leasePatch(plan, state)
+	if patch.Comment == nil && patch.IP == nil {
 		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 		return
 	}
 
-	b, _ := json.Marshal(patch)
 	id := state.Id.ValueString()
 	if id == "" {
-		id = state.Mac.ValueString()
+		id = state.Mac.Normalized()
 	}
-	path := fmt.Sprintf("/dhcp/static_lease/%s", id)
-	preq, _ := r.client.newRequest(ctx, http.MethodPut, path, bytes.NewBuffer(b))
-	pres, err := r.client.http.Do(preq)
+	updated, err := r.client.UpdateStaticLease(ctx, id, patch)
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer pres.Body.Close()
-
-	var env apiEnvelope[apiLease]
-	if err := json.NewDecoder(pres.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
-		return
-	}
-	if pres.StatusCode != http.StatusOK || !env.Success {
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("update failed: status %d, msg=%s, code=%s", pres.StatusCode, env.Msg, env.ErrorCode))
+		leaseErrors.addError(&resp.Diagnostics, "API error", fmt.Errorf("update failed: %w", err))
 		return
 	}
-	resp.Diagnostics.Append(resp.State.Set(ctx, toState(env.Result))...)
+	next := toState(*updated, plan.Comment)
+	next.OnConflict = plan.OnConflict
+	resp.Diagnostics.Append(resp.State.Set(ctx, next)...)
 }
 
 func (r *dhcpLeaseResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -238,25 +250,16 @@ func (r *dhcpLeaseResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 	if id.IsNull() || id.ValueString() == "" {
-		var mac types.String
+		var mac macValue
 		_ = req.State.GetAttribute(ctx, path.Root("mac"), &mac)
-		id = mac
+		id = types.StringValue(mac.Normalized())
 	}
 	if id.IsNull() || id.ValueString() == "" {
 		return
 	}
 
-	path := fmt.Sprintf("/dhcp/static_lease/%s", id.ValueString())
-	dreq, _ := r.client.newRequest(ctx, http.MethodDelete, path, nil)
-	dres, err := r.client.http.Do(dreq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer dres.Body.Close()
-	if dres.StatusCode != http.StatusOK && dres.StatusCode != http.StatusNoContent {
-		b, _ := io.ReadAll(dres.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", dres.StatusCode, string(b)))
+	if err := r.client.DeleteStaticLease(ctx, id.ValueString()); err != nil {
+		leaseErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
 }
@@ -266,41 +269,191 @@ func (r *dhcpLeaseResource) ImportState(ctx context.Context, req resource.Import
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("mac"), req.ID)...) // id == mac
 }
 
+// UpgradeState migrates version 0 states, where host held the raw JSON of
+// the LAN browser host. host is left null and filled by the next refresh.
+func (r *dhcpLeaseResource) UpgradeState(context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &rschema.Schema{Attributes: map[string]rschema.Attribute{
+				"id":          rschema.StringAttribute{Computed: true},
+				"mac":         rschema.StringAttribute{Required: true},
+				"ip":          rschema.StringAttribute{Required: true},
+				"comment":     rschema.StringAttribute{Optional: true, Computed: true},
+				"hostname":    rschema.StringAttribute{Computed: true},
+				"host":        rschema.StringAttribute{Computed: true},
+				"on_conflict": rschema.StringAttribute{Optional: true, Computed: true},
+			}},
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var old struct {
+					Id         types.String `tfsdk:"id"`
+					Mac        types.String `tfsdk:"mac"`
+					Ip         types.String `tfsdk:"ip"`
+					Comment    types.String `tfsdk:"comment"`
+					Hostname   types.String `tfsdk:"hostname"`
+					Host       types.String `tfsdk:"host"`
+					OnConflict types.String `tfsdk:"on_conflict"`
+				}
+				resp.Diagnostics.Append(req.State.Get(ctx, &old)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				next := leaseModel{
+					Id: old.Id, Mac: macValue{StringValue: old.Mac}, Ip: old.Ip, Comment: old.Comment, Hostname: old.Hostname,
+					Host: types.ObjectNull(leaseHostTypes), OnConflict: old.OnConflict,
+				}
+				if next.OnConflict.IsNull() {
+					next.OnConflict = types.StringValue(onConflictError)
+				}
+				resp.Diagnostics.Append(resp.State.Set(ctx, next)...)
+			},
+		},
+	}
+}
+
 // helpers
-func (r *dhcpLeaseResource) findLeaseByMacOrIP(ctx context.Context, mac, ip string) (*apiLease, string) {
-	req, _ := r.client.newRequest(ctx, http.MethodGet, "/dhcp/static_lease/", nil)
-	res, err := r.client.http.Do(req)
+
+// macChanged replaces the lease only when the MAC address really changes, not
+// when it is rewritten in another case or with other separators.
+func macChanged(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	resp.RequiresReplace = api.NormalizeMAC(req.StateValue.ValueString()) != api.NormalizeMAC(req.PlanValue.ValueString())
+}
+
+// resolveConflict handles a create refused because a lease already uses the
+// planned MAC or IP, according to on_conflict.
+func (r *dhcpLeaseResource) resolveConflict(ctx context.Context, plan leaseModel, lease api.StaticLease, diags *diag.Diagnostics) (*api.StaticLease, error) {
+	byMAC, byIP, err := r.findConflicts(ctx, lease.Mac, lease.IP)
 	if err != nil {
-		return nil, err.Error()
+		return nil, err
 	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(res.Body)
-		return nil, fmt.Sprintf("status %d: %s", res.StatusCode, string(b))
+	if byMAC == nil && byIP == nil {
+		return nil, fmt.Errorf("the Freebox reports a conflicting lease for %s / %s but none could be found", lease.Mac, lease.IP)
+	}
+
+	policy := plan.OnConflict.ValueString()
+	switch {
+	case policy == onConflictAdopt && byIP == nil:
+		// Take over the lease of this very device, then bring it to the plan.
+		tflog.Info(ctx, "Adopting existing DHCP lease", map[string]any{"id": byMAC.ID})
+		var patch api.StaticLeaseUpdate
+		if byMAC.IP != lease.IP {
+			patch.IP = &lease.IP
+		}
+		if !plan.Comment.IsNull() && !plan.Comment.IsUnknown() && byMAC.Comment != lease.Comment {
+			patch.Comment = &lease.Comment
+		}
+		if patch.IP == nil && patch.Comment == nil {
+			return byMAC, nil
+		}
+		return r.client.UpdateStaticLease(ctx, byMAC.ID, patch)
+
+	case policy == onConflictOverwrite:
+		for _, l := range []*api.StaticLease{byMAC, byIP} {
+			if l == nil {
+				continue
+			}
+			tflog.Warn(ctx, "Deleting conflicting DHCP lease", map[string]any{"id": l.ID, "ip": l.IP, "comment": l.Comment})
+			if err := r.client.DeleteStaticLease(ctx, l.ID); err != nil && !api.IsNotFound(err) {
+				return nil, err
+			}
+		}
+		return r.client.CreateStaticLease(ctx, lease)
 	}
-	var env apiEnvelope[[]apiLease]
-	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
-		return nil, err.Error()
+
+	if byMAC != nil {
+		detail := fmt.Sprintf("A static lease already exists for %s (ip %s%s).", byMAC.Mac, byMAC.IP, commentSuffix(byMAC.Comment))
+		if byIP == nil {
+			detail += fmt.Sprintf(" Import it with `terraform import <address> %s`, or set on_conflict = \"adopt\" to manage it from this resource.", byMAC.Mac)
+		}
+		diags.AddAttributeError(path.Root("mac"), "DHCP lease already exists", detail)
 	}
-	if !env.Success {
-		return nil, env.Msg
+	if byIP != nil {
+		diags.AddAttributeError(path.Root("ip"), "IP address already leased",
+			fmt.Sprintf("%s is already the static lease of another device, %s%s. on_conflict = \"adopt\" never takes over "+
+				"another device's lease; pick another ip, or set on_conflict = \"overwrite\" to delete that lease.",
+				byIP.IP, byIP.Mac, commentSuffix(byIP.Comment)))
 	}
-	for i := range env.Result {
-		if (mac != "" && env.Result[i].Mac == mac) || (ip != "" && env.Result[i].Ip == ip) {
-			return &env.Result[i], ""
+	return nil, nil
+}
+
+// findConflicts returns the lease already bound to mac, and the lease of
+// another device already using ip.
+func (r *dhcpLeaseResource) findConflicts(ctx context.Context, mac, ip string) (byMAC, byIP *api.StaticLease, err error) {
+	leases, err := r.client.ListStaticLeases(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range leases {
+		switch {
+		case api.NormalizeMAC(leases[i].Mac) == api.NormalizeMAC(mac):
+			byMAC = &leases[i]
+		case leases[i].IP == ip:
+			byIP = &leases[i]
 		}
 	}
-	return nil, ""
+	return byMAC, byIP, nil
 }
 
-func toState(l apiLease) *leaseModel {
-	host := ""
-	if len(l.Host) > 0 {
-		host = string(l.Host)
+// leasePatch returns the changes Update sends to bring the lease from state to
+// plan.
+func leasePatch(plan, state leaseModel) api.StaticLeaseUpdate {
+	var patch api.StaticLeaseUpdate
+	if !plan.Comment.IsNull() && plan.Comment.ValueString() != state.Comment.ValueString() {
+		patch.Comment = plan.Comment.ValueStringPointer()
 	}
-	id := l.Id
+	if !plan.Ip.IsNull() && plan.Ip.ValueString() != state.Ip.ValueString() {
+		patch.IP = plan.Ip.ValueStringPointer()
+	}
+	return patch
+}
+
+// leaseWritten reports whether applying plan over state creates or updates
+// the lease on the box, so that what it returns replaces the state. Unknown
+// values may turn out to change it.
+func leaseWritten(plan, state leaseModel) bool {
+	if plan.Ip.IsUnknown() || plan.Comment.IsUnknown() || plan.Mac.IsUnknown() ||
+		api.NormalizeMAC(plan.Mac.ValueString()) != api.NormalizeMAC(state.Mac.ValueString()) {
+		return true
+	}
+	patch := leasePatch(plan, state)
+	return patch.Comment != nil || patch.IP != nil
+}
+
+func commentSuffix(c string) string {
+	if c == "" {
+		return ""
+	}
+	return fmt.Sprintf(", comment %q", c)
+}
+
+// toState converts a lease read from the box; comment is the planned or stored
+// comment, see commentValue.
+func toState(l api.StaticLease, comment types.String) *leaseModel {
+	id := l.ID
 	if id == "" {
 		id = l.Mac
 	}
-	return &leaseModel{Id: types.StringValue(id), Mac: types.StringValue(l.Mac), Ip: types.StringValue(l.Ip), Comment: stringOrNull(l.Comment), Hostname: stringOrNull(l.Hostname), Host: stringOrNull(host)}
+	return &leaseModel{Id: types.StringValue(id), Mac: macOf(l.Mac), Ip: types.StringValue(l.IP), Comment: commentValue(l.Comment, comment), Hostname: stringOrNull(l.Hostname), Host: leaseHostValue(l.Host)}
+}
+
+// leaseHostTypes are the attributes of the lease host object: the stable
+// fields of the LAN browser host only, so a refresh does not churn the state.
+var leaseHostTypes = map[string]attr.Type{
+	"id":           types.StringType,
+	"primary_name": types.StringType,
+	"host_type":    types.StringType,
+	"vendor_name":  types.StringType,
+	"persistent":   types.BoolType,
+}
+
+func leaseHostValue(h *api.LanHost) types.Object {
+	if h == nil {
+		return types.ObjectNull(leaseHostTypes)
+	}
+	return types.ObjectValueMust(leaseHostTypes, map[string]attr.Value{
+		"id":           stringOrNull(h.ID),
+		"primary_name": stringOrNull(h.PrimaryName),
+		"host_type":    stringOrNull(h.HostType),
+		"vendor_name":  stringOrNull(h.VendorName),
+		"persistent":   types.BoolValue(h.Persistent),
+	})
 }
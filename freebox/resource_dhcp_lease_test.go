@@ -0,0 +1,287 @@
+package freebox
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+const testLeaseMAC = "AA:BB:CC:DD:EE:01"
+
+func testLeaseConfig(srv *fbxtest.Server, ip, comment string) string {
+	return testConfig(srv, fmt.Sprintf(`
+resource "freebox_dhcp_lease" "test" {
+  mac     = %q
+  ip      = %q
+  comment = %q
+}
+`, testLeaseMAC, ip, comment))
+}
+
+// checkLease checks the lease the box holds for testLeaseMAC.
+func checkLease(srv *fbxtest.Server, ip, comment string) resource.TestCheckFunc {
+	return checkServer(func() error {
+		for _, l := range srv.StaticLeases() {
+			if l.Mac != testLeaseMAC {
+				continue
+			}
+			if l.IP != ip || l.Comment != comment {
+				return fmt.Errorf("lease on the box is %s %q, want %s %q", l.IP, l.Comment, ip, comment)
+			}
+			return nil
+		}
+		return fmt.Errorf("no lease for %s on the box", testLeaseMAC)
+	})
+}
+
+func TestAccDhcpLease(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "freebox_dhcp_lease.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if n := len(srv.StaticLeases()); n != 0 {
+				return fmt.Errorf("%d static leases left on the box", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testLeaseConfig(srv, "192.168.1.42", "nas"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", testLeaseMAC),
+					resource.TestCheckResourceAttr(addr, "ip", "192.168.1.42"),
+					resource.TestCheckResourceAttr(addr, "comment", "nas"),
+					resource.TestCheckResourceAttr(addr, "on_conflict", "error"),
+					checkLease(srv, "192.168.1.42", "nas"),
+				),
+			},
+			{
+				ResourceName:      addr,
+				ImportState:       true,
+				ImportStateId:     testLeaseMAC,
+				ImportStateVerify: true,
+			},
+			{
+				// Changed on the box: the plan brings it back.
+				PreConfig: func() {
+					srv.PutStaticLease(api.StaticLease{Mac: testLeaseMAC, IP: "192.168.1.43", Comment: "edited"})
+				},
+				Config: testLeaseConfig(srv, "192.168.1.42", "nas"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+				},
+				Check: checkLease(srv, "192.168.1.42", "nas"),
+			},
+			{
+				// Deleted on the box: the plan creates it again.
+				PreConfig: func() { srv.DeleteStaticLease(testLeaseMAC) },
+				Config:    testLeaseConfig(srv, "192.168.1.42", "nas"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionCreate)},
+				},
+				Check: checkLease(srv, "192.168.1.42", "nas"),
+			},
+			{
+				PreConfig: func() { failTransient(srv) },
+				Config:    testLeaseConfig(srv, "192.168.1.44", "nas"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "ip", "192.168.1.44"),
+					checkLease(srv, "192.168.1.44", "nas"),
+				),
+			},
+			{
+				// The same MAC address in another form: the new form is
+				// recorded in place, not replaced, and the box form it reads
+				// back does not show as a diff.
+				Config: testConfig(srv, `
+resource "freebox_dhcp_lease" "test" {
+  mac     = "aa-bb-cc-dd-ee-01"
+  ip      = "192.168.1.44"
+  comment = "nas"
+}
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply:             []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+					PostApplyPostRefresh: []plancheck.PlanCheck{plancheck.ExpectEmptyPlan()},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", testLeaseMAC),
+					resource.TestCheckResourceAttr(addr, "mac", "aa-bb-cc-dd-ee-01"),
+					checkLease(srv, "192.168.1.44", "nas"),
+				),
+			},
+		},
+	})
+}
+
+// Renaming the host and moving its lease in the same apply: the lease update
+// returns the new hostname, which the plan must not have fixed to the old one.
+func TestAccDhcpLeaseHostRenamed(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "old", HostType: "nas", L2Ident: api.LanHostL2Ident{ID: testLeaseMAC}})
+	addr := "freebox_dhcp_lease.test"
+	config := func(name, ip string) string {
+		return testConfig(srv, fmt.Sprintf(`
+resource "freebox_lan_host" "nas" {
+  host_id      = "ether-aa:bb:cc:dd:ee:01"
+  primary_name = %q
+}
+
+resource "freebox_dhcp_lease" "test" {
+  mac = %q
+  ip  = %q
+
+  # Renamed first, so that the lease update sees the new name.
+  depends_on = [freebox_lan_host.nas]
+}
+`, name, testLeaseMAC, ip))
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("nas", "192.168.1.42"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "hostname", "nas"),
+					resource.TestCheckResourceAttr(addr, "host.primary_name", "nas"),
+				),
+			},
+			{
+				Config: config("nas2", "192.168.1.43"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownValue(addr, tfjsonpath.New("hostname")),
+						plancheck.ExpectUnknownValue(addr, tfjsonpath.New("host")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "ip", "192.168.1.43"),
+					resource.TestCheckResourceAttr(addr, "hostname", "nas2"),
+					resource.TestCheckResourceAttr(addr, "host.primary_name", "nas2"),
+				),
+			},
+			{
+				// Only the name changes: the lease is not written and keeps
+				// its plan; the next refresh reads the new name.
+				Config: config("nas3", "192.168.1.43"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionNoop)},
+				},
+			},
+			{
+				RefreshState: true,
+				Check:        resource.TestCheckResourceAttr(addr, "hostname", "nas3"),
+			},
+		},
+	})
+}
+
+// The box already holds a lease for testLeaseMAC, at another address, and
+// another device's lease on the planned address; on_conflict decides.
+func TestAccDhcpLeaseOnConflict(t *testing.T) {
+	const otherMAC = "AA:BB:CC:DD:EE:02"
+	addr := "freebox_dhcp_lease.test"
+	config := func(srv *fbxtest.Server, ip, policy string) string {
+		return testConfig(srv, fmt.Sprintf(`
+resource "freebox_dhcp_lease" "test" {
+  mac         = %q
+  ip          = %q
+  comment     = "nas"
+  on_conflict = %q
+}
+`, testLeaseMAC, ip, policy))
+	}
+	// checkLeases checks the box holds exactly want, as MAC to IP.
+	checkLeases := func(srv *fbxtest.Server, want map[string]string) resource.TestCheckFunc {
+		return checkServer(func() error {
+			got := map[string]string{}
+			for _, l := range srv.StaticLeases() {
+				got[l.Mac] = l.IP
+			}
+			if !maps.Equal(got, want) {
+				return fmt.Errorf("leases on the box are %v, want %v", got, want)
+			}
+			return nil
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		srv := newTestServer(t)
+		srv.PutStaticLease(api.StaticLease{Mac: testLeaseMAC, IP: "192.168.1.60", Comment: "old"})
+		srv.PutStaticLease(api.StaticLease{Mac: otherMAC, IP: "192.168.1.43", Comment: "printer"})
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testProviderFactories,
+			// Nothing was created, so nothing was touched.
+			CheckDestroy: checkLeases(srv, map[string]string{testLeaseMAC: "192.168.1.60", otherMAC: "192.168.1.43"}),
+			Steps: []resource.TestStep{
+				{
+					Config:      config(srv, "192.168.1.42", "error"),
+					ExpectError: regexp.MustCompile(`(?s)DHCP lease already exists.*ip 192.168.1.60.*"old".*terraform\s+import`),
+				},
+				{
+					Config:      config(srv, "192.168.1.43", "error"),
+					ExpectError: regexp.MustCompile(`(?s)IP address already leased.*another device,\s+AA:BB:CC:DD:EE:02`),
+				},
+			},
+		})
+	})
+
+	t.Run("adopt", func(t *testing.T) {
+		srv := newTestServer(t)
+		srv.PutStaticLease(api.StaticLease{Mac: testLeaseMAC, IP: "192.168.1.60", Comment: "old"})
+		srv.PutStaticLease(api.StaticLease{Mac: otherMAC, IP: "192.168.1.43", Comment: "printer"})
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					// Never another device's lease.
+					Config:      config(srv, "192.168.1.43", "adopt"),
+					ExpectError: regexp.MustCompile(`(?s)IP address already leased.*never takes over`),
+				},
+				{
+					Config: config(srv, "192.168.1.42", "adopt"),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(addr, "id", testLeaseMAC),
+						resource.TestCheckResourceAttr(addr, "ip", "192.168.1.42"),
+						resource.TestCheckResourceAttr(addr, "comment", "nas"),
+						checkLease(srv, "192.168.1.42", "nas"),
+						checkLeases(srv, map[string]string{testLeaseMAC: "192.168.1.42", otherMAC: "192.168.1.43"}),
+					),
+				},
+			},
+		})
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		srv := newTestServer(t)
+		srv.PutStaticLease(api.StaticLease{Mac: testLeaseMAC, IP: "192.168.1.60", Comment: "old"})
+		srv.PutStaticLease(api.StaticLease{Mac: otherMAC, IP: "192.168.1.43", Comment: "printer"})
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: config(srv, "192.168.1.43", "overwrite"),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(addr, "ip", "192.168.1.43"),
+						checkLease(srv, "192.168.1.43", "nas"),
+						checkLeases(srv, map[string]string{testLeaseMAC: "192.168.1.43"}),
+					),
+				},
+			},
+		})
+	})
+}
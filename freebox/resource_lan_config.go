@@ -0,0 +1,193 @@
+// Manage the LAN configuration (singleton) — API v8: /lan/config/
+package freebox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                = &lanConfigResource{}
+	_ resource.ResourceWithConfigure   = &lanConfigResource{}
+	_ resource.ResourceWithImportState = &lanConfigResource{}
+	_ resource.ResourceWithModifyPlan  = &lanConfigResource{}
+)
+
+func NewLanConfigResource() resource.Resource { return &lanConfigResource{} }
+
+type lanConfigResource struct{ client *api.Client }
+
+type lanConfigModel struct {
+	Id          types.String `tfsdk:"id"`
+	IP          types.String `tfsdk:"ip"`
+	Name        types.String `tfsdk:"name"`
+	NameDNS     types.String `tfsdk:"name_dns"`
+	NameMDNS    types.String `tfsdk:"name_mdns"`
+	NameNetbios types.String `tfsdk:"name_netbios"`
+	Mode        types.String `tfsdk:"mode"`
+}
+
+// lanConfigErrors scopes LAN errors to the attribute named by the Freebox.
+var lanConfigErrors = errorScope{attrs: []string{"ip", "name", "name_dns", "name_mdns", "name_netbios", "mode"}}
+
+func (r *lanConfigResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "freebox_lan_config"
+}
+
+func (r *lanConfigResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = rschema.Schema{
+		Description: "Manage Freebox LAN configuration (API v8): box address, mode and announced names. Singleton resource.",
+		Attributes: map[string]rschema.Attribute{
+			"id": rschema.StringAttribute{Computed: true, Description: "Synthetic ID.", PlanModifiers: keepString},
+
+			// Writable. Only the attributes set in the configuration are sent;
+			// the others keep whatever the box has.
+			"ip":           rschema.StringAttribute{Optional: true, Computed: true, Description: "Freebox IPv4 address on the LAN, also the DHCP gateway.", Validators: []validator.String{ipValidator{v4Only: true}}, PlanModifiers: keepString},
+			"name":         rschema.StringAttribute{Optional: true, Computed: true, Description: "Freebox name.", PlanModifiers: keepString},
+			"name_dns":     rschema.StringAttribute{Optional: true, Computed: true, Description: "DNS name of the Freebox on the LAN.", PlanModifiers: keepString},
+			"name_mdns":    rschema.StringAttribute{Optional: true, Computed: true, Description: "mDNS (Bonjour) name of the Freebox.", PlanModifiers: keepString},
+			"name_netbios": rschema.StringAttribute{Optional: true, Computed: true, Description: "NetBIOS name of the Freebox.", PlanModifiers: keepString},
+			"mode": rschema.StringAttribute{
+				Optional:      true,
+				Computed:      true,
+				Description:   `Network mode: "router" or "bridge". In bridge mode the Freebox no longer routes nor serves DHCP.`,
+				Validators:    []validator.String{oneOfValidator{values: []string{api.LanModeRouter, api.LanModeBridge}}},
+				PlanModifiers: keepString,
+			},
+		},
+	}
+}
+
+func (r *lanConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		r.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(r.client, "freebox_lan_config", apiMinLanConfig, &resp.Diagnostics)
+	}
+}
+
+func (r *lanConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkPermission(r.client, permSettings, "freebox_lan_config", false, req, &resp.Diagnostics)
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("ip"), &plan)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("ip"), &state)...)
+	if !plan.IsUnknown() && !plan.IsNull() && !plan.Equal(state) {
+		resp.Diagnostics.AddAttributeWarning(path.Root("ip"), "Freebox LAN address change",
+			fmt.Sprintf("The Freebox moves from %s to %s: the DHCP gateway follows, and devices on the LAN may lose "+
+				"connectivity until they renew their lease. If base_url uses the old address, update it before the next run.",
+				state.ValueString(), plan.ValueString()))
+	}
+}
+
+func (r *lanConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	cfg, err := r.apply(ctx, req.Config, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err != nil {
+		lanConfigErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, lanCfgToModel(*cfg))...)
+	tflog.Info(ctx, "Applied LAN config (create)")
+}
+
+func (r *lanConfigResource) Read(ctx context.Context, _ resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	cfg, err := r.client.GetLanConfig(ctx)
+	if err != nil {
+		lanConfigErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, lanCfgToModel(*cfg))...)
+}
+
+func (r *lanConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	cfg, err := r.apply(ctx, req.Config, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err != nil {
+		lanConfigErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, lanCfgToModel(*cfg))...)
+	tflog.Info(ctx, "Applied LAN config (update)")
+}
+
+// Delete leaves the LAN configuration in place: there is no sensible default
+// to go back to for the box address and names.
+func (r *lanConfigResource) Delete(context.Context, resource.DeleteRequest, *resource.DeleteResponse) {
+}
+
+func (r *lanConfigResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// apply sends the attributes set in config, and only those.
+func (r *lanConfigResource) apply(ctx context.Context, config tfsdk.Config, diags *diag.Diagnostics) (*api.LanConfig, error) {
+	var m lanConfigModel
+	diags.Append(config.Get(ctx, &m)...)
+	if diags.HasError() {
+		return nil, nil
+	}
+
+	var u api.LanConfigUpdate
+	set := false
+	for _, f := range []struct {
+		v   types.String
+		dst **string
+	}{
+		{m.IP, &u.IP},
+		{m.Name, &u.Name},
+		{m.NameDNS, &u.NameDNS},
+		{m.NameMDNS, &u.NameMDNS},
+		{m.NameNetbios, &u.NameNetbios},
+		{m.Mode, &u.Mode},
+	} {
+		if !f.v.IsNull() && !f.v.IsUnknown() {
+			*f.dst = f.v.ValueStringPointer()
+			set = true
+		}
+	}
+	if !set {
+		return r.client.GetLanConfig(ctx)
+	}
+	return r.client.UpdateLanConfig(ctx, u)
+}
+
+func lanCfgToModel(c api.LanConfig) *lanConfigModel {
+	return &lanConfigModel{
+		Id:          types.StringValue("lan_config"),
+		IP:          stringOrNull(c.IP),
+		Name:        stringOrNull(c.Name),
+		NameDNS:     stringOrNull(c.NameDNS),
+		NameMDNS:    stringOrNull(c.NameMDNS),
+		NameNetbios: stringOrNull(c.NameNetbios),
+		Mode:        stringOrNull(c.Mode),
+	}
+}
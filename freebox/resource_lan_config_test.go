@@ -0,0 +1,104 @@
+package freebox
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func testLanConfigConfig(srv *fbxtest.Server, nameDNS string) string {
+	return testConfig(srv, fmt.Sprintf(`
+resource "freebox_lan_config" "test" {
+  name_dns = %q
+}
+`, nameDNS))
+}
+
+// checkLanNameDNS checks the DNS name held by the box.
+func checkLanNameDNS(srv *fbxtest.Server, want string) resource.TestCheckFunc {
+	return checkServer(func() error {
+		if got := srv.LanConfig().NameDNS; got != want {
+			return fmt.Errorf("name_dns on the box is %q, want %q", got, want)
+		}
+		return nil
+	})
+}
+
+func TestAccLanConfig(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "freebox_lan_config.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		// Delete leaves the LAN configuration as it is.
+		CheckDestroy: func(*terraform.State) error { return checkLanNameDNS(srv, "box")(nil) },
+		Steps: []resource.TestStep{
+			{
+				Config: testLanConfigConfig(srv, "box"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", "lan_config"),
+					resource.TestCheckResourceAttr(addr, "name_dns", "box"),
+					// Not configured: read from the box and left alone.
+					resource.TestCheckResourceAttr(addr, "ip", "192.168.1.254"),
+					resource.TestCheckResourceAttr(addr, "name", "Freebox Server"),
+					resource.TestCheckResourceAttr(addr, "mode", "router"),
+					checkLanNameDNS(srv, "box"),
+					checkServer(func() error {
+						if got := srv.LanConfig().NameMDNS; got != "Freebox-Server" {
+							return fmt.Errorf("name_mdns on the box is %q", got)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:      addr,
+				ImportState:       true,
+				ImportStateId:     "lan_config",
+				ImportStateVerify: true,
+			},
+			{
+				// Changed on the box: the plan brings it back.
+				PreConfig: func() {
+					c := srv.LanConfig()
+					c.NameDNS = "edited"
+					srv.SetLanConfig(c)
+				},
+				Config: testLanConfigConfig(srv, "box"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+				},
+				Check: checkLanNameDNS(srv, "box"),
+			},
+			{
+				PreConfig: func() { failTransient(srv) },
+				Config: testConfig(srv, `
+resource "freebox_lan_config" "test" {
+  ip       = "192.168.2.254"
+  name_dns = "box"
+}
+
+data "freebox_lan_config" "test" {
+  depends_on = [freebox_lan_config.test]
+}
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "ip", "192.168.2.254"),
+					resource.TestCheckResourceAttr("data.freebox_lan_config.test", "ip", "192.168.2.254"),
+					resource.TestCheckResourceAttr("data.freebox_lan_config.test", "name_dns", "box"),
+					// The DHCP gateway follows the LAN address.
+					checkServer(func() error {
+						if gw := srv.DhcpConfig().Gateway; gw != "192.168.2.254" {
+							return fmt.Errorf("DHCP gateway on the box is %s", gw)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,231 @@
+// Manage LAN browser hosts (API v8): /lan/browser/{interface}/{id}
+package freebox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                = &lanHostResource{}
+	_ resource.ResourceWithConfigure   = &lanHostResource{}
+	_ resource.ResourceWithImportState = &lanHostResource{}
+	_ resource.ResourceWithModifyPlan  = &lanHostResource{}
+)
+
+func NewLanHostResource() resource.Resource { return &lanHostResource{} }
+
+type lanHostResource struct{ client *api.Client }
+
+type lanHostModel struct {
+	Id          types.String `tfsdk:"id"`
+	Interface   types.String `tfsdk:"interface"`
+	HostID      types.String `tfsdk:"host_id"`
+	PrimaryName types.String `tfsdk:"primary_name"`
+	HostType    types.String `tfsdk:"host_type"`
+	Persistent  types.Bool   `tfsdk:"persistent"`
+	Mac         types.String `tfsdk:"mac"`
+	VendorName  types.String `tfsdk:"vendor_name"`
+}
+
+// lanHostErrors scopes LAN browser errors: nodev is an unknown interface,
+// noent an unknown host.
+var lanHostErrors = errorScope{
+	codes: map[string]string{"nodev": "interface", "noent": "host_id"},
+	attrs: []string{"primary_name", "host_type", "persistent"},
+}
+
+func (r *lanHostResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "freebox_lan_host"
+}
+
+func (r *lanHostResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	resp.Schema = rschema.Schema{
+		Description: "Name, type and pin a device of the Freebox LAN browser (API v8). The device must have been seen by the box.",
+		Attributes: map[string]rschema.Attribute{
+			"id": rschema.StringAttribute{Computed: true, Description: "<interface>/<host_id>.", PlanModifiers: keepString},
+			"interface": rschema.StringAttribute{
+				Optional:      true,
+				Computed:      true,
+				Default:       stringdefault.StaticString(api.LanMainInterface),
+				Description:   `LAN browser interface of the host: "pub" (main LAN, default) or "wifiguest".`,
+				PlanModifiers: replace,
+			},
+			"host_id": rschema.StringAttribute{Required: true, Description: `LAN browser host ID, such as "ether-aa:bb:cc:dd:ee:ff".`, PlanModifiers: replace},
+
+			// Writable. Only the attributes set in the configuration are sent;
+			// the others keep whatever the box has.
+			"primary_name": rschema.StringAttribute{Optional: true, Computed: true, Description: "Name shown in Freebox OS, and as hostname on the static lease of the device.", PlanModifiers: keepString},
+			"host_type": rschema.StringAttribute{
+				Optional:      true,
+				Computed:      true,
+				Description:   `Device type, shown as its icon in Freebox OS (e.g. "workstation", "smartphone", "nas").`,
+				Validators:    []validator.String{oneOfValidator{values: api.LanHostTypes}},
+				PlanModifiers: keepString,
+			},
+			"persistent": rschema.BoolAttribute{Optional: true, Computed: true, Description: "Keep the host in the LAN browser when it has been inactive for a while.", PlanModifiers: keepBool},
+
+			// Read-only.
+			"mac":         rschema.StringAttribute{Computed: true, Description: "MAC address of the host.", PlanModifiers: keepString},
+			"vendor_name": rschema.StringAttribute{Computed: true, Description: "Vendor, from the MAC address prefix.", PlanModifiers: keepString},
+		},
+	}
+}
+
+func (r *lanHostResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		r.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(r.client, "freebox_lan_host", apiMinLanBrowser, &resp.Diagnostics)
+	}
+}
+
+func (r *lanHostResource) ModifyPlan(_ context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkPermission(r.client, permSettings, "freebox_lan_host", false, req, &resp.Diagnostics)
+}
+
+func (r *lanHostResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	var plan lanHostModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	iface, id := plan.Interface.ValueString(), plan.HostID.ValueString()
+
+	h, err := r.apply(ctx, iface, id, req.Config, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if api.IsNotFound(err) {
+		resp.Diagnostics.AddAttributeError(path.Root("host_id"), "LAN host not found",
+			fmt.Sprintf("The Freebox has not seen host %q on interface %q. The LAN browser only knows devices that "+
+				"have been connected; list them with the freebox_lan_hosts data source.", id, iface))
+		return
+	}
+	if err != nil {
+		lanHostErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, toLanHostState(iface, *h))...)
+	tflog.Info(ctx, "Managing LAN host", map[string]any{"interface": iface, "id": id})
+}
+
+func (r *lanHostResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	var state lanHostModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	iface := state.Interface.ValueString()
+	h, err := r.client.GetLanHost(ctx, iface, state.HostID.ValueString())
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		lanHostErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, toLanHostState(iface, *h))...)
+}
+
+func (r *lanHostResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+	var state lanHostModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	iface := state.Interface.ValueString()
+
+	h, err := r.apply(ctx, iface, state.HostID.ValueString(), req.Config, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err != nil {
+		lanHostErrors.addError(&resp.Diagnostics, "API error", fmt.Errorf("update failed: %w", err))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, toLanHostState(iface, *h))...)
+}
+
+// Delete leaves the host in the LAN browser with its current name and type:
+// the box owns the list of hosts and there is no earlier name to go back to.
+func (r *lanHostResource) Delete(context.Context, resource.DeleteRequest, *resource.DeleteResponse) {
+}
+
+// ImportState accepts <interface>/<host_id>, e.g. pub/ether-aa:bb:cc:dd:ee:ff.
+func (r *lanHostResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	iface, id, ok := strings.Cut(req.ID, "/")
+	if !ok || iface == "" || id == "" {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("Expected <interface>/<host_id> (e.g. pub/ether-aa:bb:cc:dd:ee:ff), got %q.", req.ID))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("interface"), iface)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("host_id"), id)...)
+}
+
+// apply sends the attributes set in config, and only those.
+func (r *lanHostResource) apply(ctx context.Context, iface, id string, config tfsdk.Config, diags *diag.Diagnostics) (*api.LanHost, error) {
+	var m lanHostModel
+	diags.Append(config.Get(ctx, &m)...)
+	if diags.HasError() {
+		return nil, nil
+	}
+
+	var u api.LanHostUpdate
+	if !m.PrimaryName.IsNull() && !m.PrimaryName.IsUnknown() {
+		u.PrimaryName = m.PrimaryName.ValueStringPointer()
+	}
+	if !m.HostType.IsNull() && !m.HostType.IsUnknown() {
+		u.HostType = m.HostType.ValueStringPointer()
+	}
+	if !m.Persistent.IsNull() && !m.Persistent.IsUnknown() {
+		u.Persistent = m.Persistent.ValueBoolPointer()
+	}
+	if u.PrimaryName == nil && u.HostType == nil && u.Persistent == nil {
+		return r.client.GetLanHost(ctx, iface, id)
+	}
+	return r.client.UpdateLanHost(ctx, iface, id, u)
+}
+
+func toLanHostState(iface string, h api.LanHost) *lanHostModel {
+	return &lanHostModel{
+		Id:          types.StringValue(iface + "/" + h.ID),
+		Interface:   types.StringValue(iface),
+		HostID:      types.StringValue(h.ID),
+		PrimaryName: stringOrNull(h.PrimaryName),
+		HostType:    stringOrNull(h.HostType),
+		Persistent:  types.BoolValue(h.Persistent),
+		Mac:         stringOrNull(h.L2Ident.ID),
+		VendorName:  stringOrNull(h.VendorName),
+	}
+}
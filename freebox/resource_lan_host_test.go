@@ -0,0 +1,135 @@
+package freebox
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+const testLanHostID = "ether-aa:bb:cc:dd:ee:01"
+
+func testLanHostConfig(srv *fbxtest.Server, iface, name string) string {
+	return testConfig(srv, fmt.Sprintf(`
+resource "freebox_lan_host" "test" {
+  interface    = %q
+  host_id      = %q
+  primary_name = %q
+  host_type    = "nas"
+}
+`, iface, testLanHostID, name))
+}
+
+// checkLanHost checks the name and type the box holds for testLanHostID.
+func checkLanHost(srv *fbxtest.Server, iface, name, hostType string) resource.TestCheckFunc {
+	return checkServer(func() error {
+		for _, h := range srv.LanHosts(iface) {
+			if h.ID != testLanHostID {
+				continue
+			}
+			if h.PrimaryName != name || h.HostType != hostType {
+				return fmt.Errorf("host on the box is %q %q, want %q %q", h.PrimaryName, h.HostType, name, hostType)
+			}
+			return nil
+		}
+		return fmt.Errorf("no host %s on %s", testLanHostID, iface)
+	})
+}
+
+func TestAccLanHost(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "old", HostType: "workstation", VendorName: "Acme", L2Ident: api.LanHostL2Ident{ID: testLeaseMAC}})
+	addr := "freebox_lan_host.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		// Delete leaves the host in the LAN browser as it is.
+		CheckDestroy: func(*terraform.State) error { return checkLanHost(srv, api.LanMainInterface, "nas2", "nas")(nil) },
+		Steps: []resource.TestStep{
+			{
+				Config: testLanHostConfig(srv, api.LanMainInterface, "nas"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", "pub/"+testLanHostID),
+					resource.TestCheckResourceAttr(addr, "interface", "pub"),
+					resource.TestCheckResourceAttr(addr, "mac", testLeaseMAC),
+					resource.TestCheckResourceAttr(addr, "vendor_name", "Acme"),
+					resource.TestCheckResourceAttr(addr, "persistent", "false"),
+					checkLanHost(srv, api.LanMainInterface, "nas", "nas"),
+				),
+			},
+			{
+				ResourceName:      addr,
+				ImportState:       true,
+				ImportStateId:     "pub/" + testLanHostID,
+				ImportStateVerify: true,
+			},
+			{
+				// Changed on the box: the plan brings it back.
+				PreConfig: func() {
+					h := srv.LanHosts(api.LanMainInterface)[0]
+					h.PrimaryName, h.HostType = "edited", "smartphone"
+					srv.PutLanHost(api.LanMainInterface, h)
+				},
+				Config: testLanHostConfig(srv, api.LanMainInterface, "nas"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+				},
+				Check: checkLanHost(srv, api.LanMainInterface, "nas", "nas"),
+			},
+			{
+				PreConfig: func() { failTransient(srv) },
+				Config:    testLanHostConfig(srv, api.LanMainInterface, "nas2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "primary_name", "nas2"),
+					checkLanHost(srv, api.LanMainInterface, "nas2", "nas"),
+				),
+			},
+			{
+				// Gone from the LAN browser: the host must be seen again first.
+				PreConfig:   func() { srv.DeleteLanHost(api.LanMainInterface, testLanHostID) },
+				Config:      testLanHostConfig(srv, api.LanMainInterface, "nas2"),
+				ExpectError: regexp.MustCompile("LAN host not found"),
+			},
+			{
+				PreConfig: func() {
+					srv.PutLanHost(api.LanMainInterface, api.LanHost{PrimaryName: "nas2", HostType: "nas", L2Ident: api.LanHostL2Ident{ID: testLeaseMAC}})
+				},
+				Config: testLanHostConfig(srv, api.LanMainInterface, "nas2"),
+				Check:  checkLanHost(srv, api.LanMainInterface, "nas2", "nas"),
+			},
+		},
+	})
+}
+
+func TestAccLanHostImport(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PutLanHost("wifiguest", api.LanHost{PrimaryName: "phone", HostType: "smartphone", L2Ident: api.LanHostL2Ident{ID: testLeaseMAC}})
+	addr := "freebox_lan_host.test"
+	config := testLanHostConfig(srv, "wifiguest", "guest")
+
+	importError := func(id, msg string) resource.TestStep {
+		return resource.TestStep{Config: config, ResourceName: addr, ImportState: true, ImportStateId: id, ExpectError: regexp.MustCompile(msg)}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", "wifiguest/"+testLanHostID),
+					checkLanHost(srv, "wifiguest", "guest", "nas"),
+				),
+			},
+			{Config: config, ResourceName: addr, ImportState: true, ImportStateId: "wifiguest/" + testLanHostID, ImportStateVerify: true},
+			importError("pub/"+testLanHostID, "Cannot import non-existent remote object"),
+			importError(testLanHostID, "Invalid import ID"),
+			importError("wifiguest/", "Invalid import ID"),
+		},
+	})
+}
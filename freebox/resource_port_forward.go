@@ -2,13 +2,12 @@
 package freebox
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -16,35 +15,35 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure interfaces
 var (
-	_ resource.Resource                = &portForwardResource{}
-	_ resource.ResourceWithConfigure   = &portForwardResource{}
-	_ resource.ResourceWithImportState = &portForwardResource{}
+	_ resource.Resource                   = &portForwardResource{}
+	_ resource.ResourceWithConfigure      = &portForwardResource{}
+	_ resource.ResourceWithImportState    = &portForwardResource{}
+	_ resource.ResourceWithModifyPlan     = &portForwardResource{}
+	_ resource.ResourceWithMoveState      = &portForwardResource{}
+	_ resource.ResourceWithValidateConfig = &portForwardResource{}
 )
 
-func NewPortForwardingResource() resource.Resource { return &portForwardResource{} }
-
-// ---------- API models ----------
-
-type apiPortForward struct {
-	ID           int             `json:"id,omitempty"` // omitempty so create doesn't send 0
-	Enabled      bool            `json:"enabled"`
-	IpProto      string          `json:"ip_proto"`       // "tcp" | "udp"
-	WanPortStart int             `json:"wan_port_start"` // required
-	WanPortEnd   int             `json:"wan_port_end"`   // required
-	LanIP        string          `json:"lan_ip"`         // required
-	LanPort      int             `json:"lan_port"`       // required
-	SrcIP        string          `json:"src_ip"`         // default "0.0.0.0"
-	Comment      string          `json:"comment,omitempty"`
-	Hostname     string          `json:"hostname,omitempty"` // read-only
-	Host         json.RawMessage `json:"host,omitempty"`     // read-only
+const (
+	portForwardingType = "freebox_port_forwarding"
+	// portForwardAlias is the name the resource was first registered under,
+	// kept so existing configurations keep working.
+	portForwardAlias = "freebox_port_forward"
+)
+
+func NewPortForwardingResource() resource.Resource {
+	return &portForwardResource{typeName: portForwardingType}
 }
 
-// NOTE: apiEnvelope[T] is defined elsewhere in this package.
+// NewPortForwardResource registers the deprecated freebox_port_forward alias.
+func NewPortForwardResource() resource.Resource {
+	return &portForwardResource{typeName: portForwardAlias}
+}
 
 // ---------- TF model ----------
 
@@ -61,16 +60,33 @@ type pfModel struct {
 	Hostname     types.String `tfsdk:"hostname"`
 }
 
-type portForwardResource struct{ client *Client }
+type portForwardResource struct {
+	client   *api.Client
+	typeName string
+}
+
+// pfErrors scopes fw/redir errors to the attribute they concern.
+var pfErrors = errorScope{
+	codes: map[string]string{"exist": "wan_port_start"},
+	attrs: []string{"enabled", "ip_proto", "wan_port_start", "wan_port_end", "lan_ip", "lan_port", "src_ip", "comment"},
+}
 
 // ---------- Resource wiring ----------
 
 func (r *portForwardResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = "freebox_port_forward"
+	resp.TypeName = r.typeName
 }
 
 func (r *portForwardResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
-	resp.Schema = rschema.Schema{
+	resp.Schema = portForwardSchema()
+	if r.typeName == portForwardAlias {
+		resp.Schema.DeprecationMessage = "Use freebox_port_forwarding instead, with a moved block from this resource to keep the existing rule. " +
+			"freebox_port_forward will be removed in a future major version."
+	}
+}
+
+func portForwardSchema() rschema.Schema {
+	return rschema.Schema{
 		Description: "Manage Freebox Port Forwarding rules (fw/redir).",
 		Attributes: map[string]rschema.Attribute{
 			"id": rschema.Int64Attribute{
@@ -89,28 +105,34 @@ func (r *portForwardResource) Schema(_ context.Context, _ resource.SchemaRequest
 				Computed:    true,
 				Default:     stringdefault.StaticString("tcp"),
 				Description: `IP protocol ("tcp" or "udp").`,
+				Validators:  []validator.String{oneOfValidator{values: []string{"tcp", "udp"}}},
 			},
 			"wan_port_start": rschema.Int64Attribute{
 				Required:    true,
 				Description: "External (WAN) start port.",
+				Validators:  []validator.Int64{portValidator{}},
 			},
 			"wan_port_end": rschema.Int64Attribute{
 				Required:    true,
 				Description: "External (WAN) end port.",
+				Validators:  []validator.Int64{portValidator{}},
 			},
 			"lan_ip": rschema.StringAttribute{
 				Required:    true,
 				Description: "Target LAN IP.",
+				Validators:  []validator.String{ipValidator{v4Only: true}},
 			},
 			"lan_port": rschema.Int64Attribute{
 				Required:    true,
 				Description: "Target LAN start port (end is lan_port + wan_port_end - wan_port_start).",
+				Validators:  []validator.Int64{portValidator{}},
 			},
 			"src_ip": rschema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString("0.0.0.0"),
+				Default:     stringdefault.StaticString(anySource),
 				Description: "Source IP filter. Use 0.0.0.0 for any source.",
+				Validators:  []validator.String{ipValidator{v4Only: true}},
 			},
 			"comment": rschema.StringAttribute{
 				Optional:    true,
@@ -127,12 +149,84 @@ func (r *portForwardResource) Schema(_ context.Context, _ resource.SchemaRequest
 	}
 }
 
-func (r *portForwardResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+func (r *portForwardResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData != nil {
-		r.client = req.ProviderData.(*Client)
+		r.client = req.ProviderData.(*api.Client)
+		requireAPIVersion(r.client, r.typeName, apiMinPortForward, &resp.Diagnostics)
 	}
 }
 
+func (r *portForwardResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg pfModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() || cfg.WanPortStart.IsUnknown() || cfg.WanPortEnd.IsUnknown() {
+		return
+	}
+	start, end := cfg.WanPortStart.ValueInt64(), cfg.WanPortEnd.ValueInt64()
+	if end < start {
+		resp.Diagnostics.AddAttributeError(path.Root("wan_port_end"), "Invalid port range",
+			fmt.Sprintf("wan_port_end (%d) is lower than wan_port_start (%d).", end, start))
+		return
+	}
+	if !cfg.LanPort.IsUnknown() && cfg.LanPort.ValueInt64()+end-start > 65535 {
+		resp.Diagnostics.AddAttributeError(path.Root("lan_port"), "Invalid port range",
+			fmt.Sprintf("The LAN range %d-%d goes past port 65535.", cfg.LanPort.ValueInt64(), cfg.LanPort.ValueInt64()+end-start))
+	}
+}
+
+func (r *portForwardResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkPermission(r.client, permSettings, r.typeName, true, req, &resp.Diagnostics)
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// hostname is the LAN browser name of lan_ip, which the box resolves
+	// again on every write: it is only kept from state when the rule is not
+	// updated.
+	if !req.State.Raw.IsNull() && !req.Plan.Raw.Equal(req.State.Raw) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("hostname"), types.StringUnknown())...)
+	}
+
+	var plan, state types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("lan_ip"), &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("lan_ip"), &state)...)
+	}
+	if plan.IsUnknown() || plan.Equal(state) {
+		return
+	}
+	checkInLAN(fetchLANSubnet(ctx, r.client), path.Root("lan_ip"), plan, &resp.Diagnostics)
+}
+
+// MoveState lets a moved block turn a freebox_port_forward into a
+// freebox_port_forwarding without recreating the rule: both share one schema.
+func (r *portForwardResource) MoveState(_ context.Context) []resource.StateMover {
+	if r.typeName != portForwardingType {
+		return nil
+	}
+	source := portForwardSchema()
+	return []resource.StateMover{{
+		SourceSchema: &source,
+		StateMover: func(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+			// The provider address is not checked: local builds and mirrors
+			// register this provider under other sources.
+			if req.SourceTypeName != portForwardAlias {
+				return
+			}
+			if req.SourceState == nil {
+				resp.Diagnostics.AddError("Unable to move "+portForwardAlias, "The source state could not be decoded against the "+portForwardAlias+" schema.")
+				return
+			}
+			var state pfModel
+			resp.Diagnostics.Append(req.SourceState.Get(ctx, &state)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			resp.Diagnostics.Append(resp.TargetState.Set(ctx, state)...)
+		},
+	}}
+}
+
 // ---------- CRUD ----------
 
 func (r *portForwardResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -147,38 +241,13 @@ func (r *portForwardResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 
-	payload := apiPortForward{
-		Enabled:      plan.Enabled.ValueBool(),
-		IpProto:      plan.IpProto.ValueString(),
-		WanPortStart: int(plan.WanPortStart.ValueInt64()),
-		WanPortEnd:   int(plan.WanPortEnd.ValueInt64()),
-		LanIP:        plan.LanIP.ValueString(),
-		LanPort:      int(plan.LanPort.ValueInt64()),
-		SrcIP:        plan.SrcIP.ValueString(),
-		Comment:      plan.Comment.ValueString(),
-	}
-	b, _ := json.Marshal(payload)
-
-	hreq, _ := r.client.newRequest(ctx, http.MethodPost, "/fw/redir/", bytes.NewBuffer(b))
-	hres, err := r.client.http.Do(hreq)
+	created, err := r.client.CreatePortForward(ctx, pfPayload(plan))
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer hres.Body.Close()
-
-	var env apiEnvelope[apiPortForward]
-	_ = json.NewDecoder(hres.Body).Decode(&env)
-	if hres.StatusCode != http.StatusOK && hres.StatusCode != http.StatusCreated {
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d (msg=%s, code=%s)", hres.StatusCode, env.Msg, env.ErrorCode))
-		return
-	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("success=false (msg=%s, code=%s)", env.Msg, env.ErrorCode))
+		pfErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, toPFState(env.Result))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, toPFState(*created, plan.Comment))...)
 }
 
 func (r *portForwardResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -199,36 +268,17 @@ func (r *portForwardResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
-	path := fmt.Sprintf("/fw/redir/%d", id)
-	hreq, _ := r.client.newRequest(ctx, http.MethodGet, path, nil)
-	hres, err := r.client.http.Do(hreq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer hres.Body.Close()
-
-	if hres.StatusCode == http.StatusNotFound {
+	pf, err := r.client.GetPortForward(ctx, int(id))
+	if api.IsNotFound(err) {
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	if hres.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(hres.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", hres.StatusCode, string(body)))
-		return
-	}
-
-	var env apiEnvelope[apiPortForward]
-	if err := json.NewDecoder(hres.Body).Decode(&env); err != nil {
-		resp.Diagnostics.AddError("Decode error", err.Error())
-		return
-	}
-	if !env.Success {
-		resp.Diagnostics.AddError("API error", env.Msg)
+	if err != nil {
+		pfErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, toPFState(env.Result))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, toPFState(*pf, state.Comment))...)
 }
 
 func (r *portForwardResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -255,37 +305,13 @@ func (r *portForwardResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 
-	// Include ID in payload so it matches the URL (the API validates this)
-	payload := apiPortForward{
-		ID:           int(id),
-		Enabled:      plan.Enabled.ValueBool(),
-		IpProto:      plan.IpProto.ValueString(),
-		WanPortStart: int(plan.WanPortStart.ValueInt64()),
-		WanPortEnd:   int(plan.WanPortEnd.ValueInt64()),
-		LanIP:        plan.LanIP.ValueString(),
-		LanPort:      int(plan.LanPort.ValueInt64()),
-		SrcIP:        plan.SrcIP.ValueString(),
-		Comment:      plan.Comment.ValueString(),
-	}
-	b, _ := json.Marshal(payload)
-
-	path := fmt.Sprintf("/fw/redir/%d", id)
-	hreq, _ := r.client.newRequest(ctx, http.MethodPut, path, bytes.NewBuffer(b))
-	hres, err := r.client.http.Do(hreq)
+	updated, err := r.client.UpdatePortForward(ctx, int(id), pfUpdatePayload(plan))
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
-		return
-	}
-	defer hres.Body.Close()
-
-	var env apiEnvelope[apiPortForward]
-	_ = json.NewDecoder(hres.Body).Decode(&env)
-	if hres.StatusCode != http.StatusOK || !env.Success {
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("update failed: status %d, msg=%s, code=%s", hres.StatusCode, env.Msg, env.ErrorCode))
+		pfErrors.addError(&resp.Diagnostics, "API error", fmt.Errorf("update failed: %w", err))
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, toPFState(env.Result))...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, toPFState(*updated, plan.Comment))...)
 }
 
 func (r *portForwardResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -303,31 +329,81 @@ func (r *portForwardResource) Delete(ctx context.Context, req resource.DeleteReq
 		return
 	}
 
-	path := fmt.Sprintf("/fw/redir/%d", id.ValueInt64())
-	hreq, _ := r.client.newRequest(ctx, http.MethodDelete, path, nil)
-	hres, err := r.client.http.Do(hreq)
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	if err := r.client.DeletePortForward(ctx, int(id.ValueInt64())); err != nil {
+		pfErrors.addError(&resp.Diagnostics, "API error", err)
 		return
 	}
-	defer hres.Body.Close()
+}
 
-	// Freebox typically returns 200 OK; accept 204 too
-	if hres.StatusCode != http.StatusOK && hres.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(hres.Body)
-		resp.Diagnostics.AddError("API error", fmt.Sprintf("status %d: %s", hres.StatusCode, string(body)))
+// Import by rule id ("7") or by protocol and WAN port ("tcp:8080").
+func (r *portForwardResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if id, err := strconv.ParseInt(req.ID, 10, 64); err == nil && id > 0 {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 		return
 	}
-}
 
-// Import by id
-func (r *portForwardResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	proto, portStr, ok := strings.Cut(req.ID, ":")
+	port, err := strconv.Atoi(portStr)
+	proto = strings.ToLower(proto)
+	if !ok || err != nil || (proto != "tcp" && proto != "udp") {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("Expected a rule id (e.g. 7) or <ip_proto>:<wan_port> (e.g. tcp:8080), got %q.", req.ID))
+		return
+	}
+	if r.client == nil {
+		resp.Diagnostics.AddError("Client not configured", "Provider client is nil")
+		return
+	}
+
+	rules, err := r.client.ListPortForwards(ctx)
+	if err != nil {
+		pfErrors.addError(&resp.Diagnostics, "API error", err)
+		return
+	}
+	for _, pf := range rules {
+		if pf.IpProto == proto && pf.WanPortStart <= port && port <= pf.WanPortEnd {
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(pf.ID))...)
+			return
+		}
+	}
+	resp.Diagnostics.AddError("Port forwarding not found",
+		fmt.Sprintf("No %s port forwarding covers WAN port %d.", proto, port))
 }
 
 // ---------- helpers ----------
 
-func toPFState(p apiPortForward) *pfModel {
+func pfPayload(m pfModel) api.PortForward {
+	return api.PortForward{
+		Enabled:      m.Enabled.ValueBool(),
+		IpProto:      m.IpProto.ValueString(),
+		WanPortStart: int(m.WanPortStart.ValueInt64()),
+		WanPortEnd:   int(m.WanPortEnd.ValueInt64()),
+		LanIP:        m.LanIP.ValueString(),
+		LanPort:      int(m.LanPort.ValueInt64()),
+		SrcIP:        m.SrcIP.ValueString(),
+		Comment:      m.Comment.ValueString(),
+	}
+}
+
+// pfUpdatePayload is pfPayload for an update, which always carries the
+// comment: a null or empty comment clears the one on the box.
+func pfUpdatePayload(m pfModel) api.PortForwardUpdate {
+	p := pfPayload(m)
+	return api.PortForwardUpdate{
+		Enabled:      p.Enabled,
+		IpProto:      p.IpProto,
+		WanPortStart: p.WanPortStart,
+		WanPortEnd:   p.WanPortEnd,
+		LanIP:        p.LanIP,
+		LanPort:      p.LanPort,
+		SrcIP:        p.SrcIP,
+		Comment:      p.Comment,
+	}
+}
+
+// toPFState converts a rule read from the box; comment is the planned or
+// stored comment, see commentValue.
+func toPFState(p api.PortForward, comment types.String) *pfModel {
 	return &pfModel{
 		ID:           types.Int64Value(int64(p.ID)),
 		Enabled:      types.BoolValue(p.Enabled),
@@ -336,8 +412,8 @@ func toPFState(p apiPortForward) *pfModel {
 		WanPortEnd:   types.Int64Value(int64(p.WanPortEnd)),
 		LanIP:        types.StringValue(p.LanIP),
 		LanPort:      types.Int64Value(int64(p.LanPort)),
-		SrcIP:        stringOrNull(p.SrcIP),
-		Comment:      stringOrNull(p.Comment),
+		SrcIP:        srcIPValue(p.SrcIP),
+		Comment:      commentValue(p.Comment, comment),
 		Hostname:     stringOrNull(p.Hostname),
 	}
 }
@@ -0,0 +1,241 @@
+package freebox
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/fbxtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// testPortForwardConfig forwards tcp 8080-8090 to lanPort on 192.168.1.42.
+// extra is added to the resource body.
+func testPortForwardConfig(srv *fbxtest.Server, lanPort int, extra string) string {
+	return testConfig(srv, fmt.Sprintf(`
+resource "freebox_port_forwarding" "test" {
+  wan_port_start = 8080
+  wan_port_end   = 8090
+  lan_ip         = "192.168.1.42"
+  lan_port       = %d
+  %s
+}
+`, lanPort, extra))
+}
+
+// checkPortForward checks the only rule held by the box.
+func checkPortForward(srv *fbxtest.Server, lanPort int, comment string) resource.TestCheckFunc {
+	return checkServer(func() error {
+		rules := srv.PortForwards()
+		if len(rules) != 1 {
+			return fmt.Errorf("%d port forwardings on the box, want 1", len(rules))
+		}
+		if pf := rules[0]; pf.LanPort != lanPort || pf.Comment != comment {
+			return fmt.Errorf("rule on the box forwards to port %d with comment %q, want %d %q", pf.LanPort, pf.Comment, lanPort, comment)
+		}
+		return nil
+	})
+}
+
+func TestAccPortForwarding(t *testing.T) {
+	srv := newTestServer(t)
+	testLanHostAt(srv, "AA:BB:CC:DD:EE:42", "nas", "192.168.1.42")
+	testLanHostAt(srv, "AA:BB:CC:DD:EE:43", "web", "192.168.1.43")
+	addr := "freebox_port_forwarding.test"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if n := len(srv.PortForwards()); n != 0 {
+				return fmt.Errorf("%d port forwardings left on the box", n)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testPortForwardConfig(srv, 80, `comment = "web"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "id", "1"),
+					resource.TestCheckResourceAttr(addr, "enabled", "true"),
+					resource.TestCheckResourceAttr(addr, "ip_proto", "tcp"),
+					resource.TestCheckResourceAttr(addr, "src_ip", "0.0.0.0"),
+					resource.TestCheckResourceAttr(addr, "comment", "web"),
+					resource.TestCheckResourceAttr(addr, "hostname", "nas"),
+					checkPortForward(srv, 80, "web"),
+				),
+			},
+			{
+				ResourceName:      addr,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// Changed on the box: the plan brings it back.
+				PreConfig: func() {
+					pf := srv.PortForwards()[0]
+					pf.LanPort = 8000
+					srv.PutPortForward(pf)
+				},
+				Config: testPortForwardConfig(srv, 80, `comment = "web"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionUpdate)},
+				},
+				Check: checkPortForward(srv, 80, "web"),
+			},
+			{
+				// Deleted on the box: the plan creates it again.
+				PreConfig: func() { srv.DeletePortForward(srv.PortForwards()[0].ID) },
+				Config:    testPortForwardConfig(srv, 80, `comment = "web"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(addr, plancheck.ResourceActionCreate)},
+				},
+				Check: checkPortForward(srv, 80, "web"),
+			},
+			{
+				PreConfig: func() { failTransient(srv) },
+				Config:    testPortForwardConfig(srv, 8080, `comment = "web"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "lan_port", "8080"),
+					checkPortForward(srv, 8080, "web"),
+				),
+			},
+			{
+				// The box trims comments; the configured form is kept.
+				Config: testPortForwardConfig(srv, 8080, `comment = "  web  "`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "comment", "  web  "),
+					checkPortForward(srv, 8080, "web"),
+				),
+			},
+			{
+				Config: testPortForwardConfig(srv, 8080, `comment = ""`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "comment", ""),
+					checkPortForward(srv, 8080, ""),
+				),
+			},
+			{
+				PreConfig: func() {
+					pf := srv.PortForwards()[0]
+					pf.Comment = "web"
+					srv.PutPortForward(pf)
+				},
+				Config: testPortForwardConfig(srv, 8080, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr(addr, "comment"),
+					checkPortForward(srv, 8080, ""),
+				),
+			},
+			{
+				Config: testPortForwardConfig(srv, 8080, `src_ip = "203.0.113.7"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "src_ip", "203.0.113.7"),
+					checkServer(func() error {
+						if ip := srv.PortForwards()[0].SrcIP; ip != "203.0.113.7" {
+							return fmt.Errorf("src_ip on the box is %q", ip)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// An empty src_ip on the box reads as any source.
+				PreConfig: func() {
+					pf := srv.PortForwards()[0]
+					pf.SrcIP = ""
+					srv.PutPortForward(pf)
+				},
+				Config: testPortForwardConfig(srv, 8080, ""),
+				Check:  resource.TestCheckResourceAttr(addr, "src_ip", anySource),
+			},
+			{
+				// Another host: the box answers with its name.
+				Config: testConfig(srv, `
+resource "freebox_port_forwarding" "test" {
+  wan_port_start = 8080
+  wan_port_end   = 8090
+  lan_ip         = "192.168.1.43"
+  lan_port       = 8080
+}
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectUnknownValue(addr, tfjsonpath.New("hostname"))},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(addr, "lan_ip", "192.168.1.43"),
+					resource.TestCheckResourceAttr(addr, "hostname", "web"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPortForwardingImport(t *testing.T) {
+	srv := newTestServer(t)
+	addr := "freebox_port_forwarding.test"
+	config := testPortForwardConfig(srv, 80, `comment = "web"`)
+
+	step := func(id string) resource.TestStep {
+		return resource.TestStep{Config: config, ResourceName: addr, ImportState: true, ImportStateId: id, ImportStateVerify: true}
+	}
+	importError := func(id, msg string) resource.TestStep {
+		return resource.TestStep{Config: config, ResourceName: addr, ImportState: true, ImportStateId: id, ExpectError: regexp.MustCompile(msg)}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{Config: config},
+			step("1"),
+			step("tcp:8080"),
+			step("tcp:8085"), // inside the WAN range
+			step("TCP:8090"),
+			importError("tcp:8091", "No tcp port forwarding covers WAN port 8091"),
+			importError("udp:8080", "No udp port forwarding covers WAN port 8080"),
+			importError("2", "Cannot import non-existent remote object"),
+			importError("web", "Invalid import ID"),
+		},
+	})
+}
+
+func TestAccPortForwardingMoveState(t *testing.T) {
+	srv := newTestServer(t)
+	rule := `
+  wan_port_start = 8080
+  wan_port_end   = 8090
+  lan_ip         = "192.168.1.42"
+  lan_port       = 80
+  comment        = "web"
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_8_0)},
+		Steps: []resource.TestStep{
+			{
+				Config: testConfig(srv, `resource "freebox_port_forward" "old" {`+rule+"}\n"),
+			},
+			{
+				Config: testConfig(srv, `resource "freebox_port_forwarding" "new" {`+rule+`}
+
+moved {
+  from = freebox_port_forward.old
+  to   = freebox_port_forwarding.new
+}
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("freebox_port_forwarding.new", plancheck.ResourceActionNoop)},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("freebox_port_forwarding.new", "id", "1"),
+					resource.TestCheckResourceAttr("freebox_port_forwarding.new", "comment", "web"),
+					checkPortForward(srv, 80, "web"),
+				),
+			},
+		},
+	})
+}
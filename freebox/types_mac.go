@@ -0,0 +1,78 @@
+package freebox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darshaner/terraform-provider-freebox/freebox/api"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = macType{}
+	_ basetypes.StringValuableWithSemanticEquals = macValue{}
+)
+
+// macType is a string attribute holding a MAC address. Values that differ
+// only in case or separator (aa-bb-cc-dd-ee-ff, AA:BB:CC:DD:EE:FF) are
+// semantically equal, so the form the box returns never shows as a diff.
+type macType struct{ basetypes.StringType }
+
+func (t macType) Equal(o attr.Type) bool {
+	other, ok := o.(macType)
+	return ok && t.StringType.Equal(other.StringType)
+}
+
+func (t macType) String() string { return "macType" }
+
+func (t macType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return macValue{StringValue: in}, nil
+}
+
+func (t macType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	v, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type %T", v)
+	}
+	return macValue{StringValue: s}, nil
+}
+
+func (t macType) ValueType(context.Context) attr.Value { return macValue{} }
+
+// macValue is a value of macType.
+type macValue struct{ basetypes.StringValue }
+
+func macOf(s string) macValue { return macValue{StringValue: basetypes.NewStringValue(s)} }
+
+func (v macValue) Equal(o attr.Value) bool {
+	other, ok := o.(macValue)
+	return ok && v.StringValue.Equal(other.StringValue)
+}
+
+func (v macValue) Type(context.Context) attr.Type { return macType{} }
+
+// StringSemanticEquals compares both MAC addresses once normalised.
+func (v macValue) StringSemanticEquals(_ context.Context, o basetypes.StringValuable) (bool, diag.Diagnostics) {
+	other, ok := o.(macValue)
+	if !ok {
+		return false, nil
+	}
+	a, b := api.NormalizeMAC(v.ValueString()), api.NormalizeMAC(other.ValueString())
+	return a != "" && a == b, nil
+}
+
+// Normalized returns the MAC address in the form the box uses, or the raw
+// value when it is not a valid MAC address.
+func (v macValue) Normalized() string {
+	if mac := api.NormalizeMAC(v.ValueString()); mac != "" {
+		return mac
+	}
+	return v.ValueString()
+}
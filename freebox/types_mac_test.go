@@ -0,0 +1,38 @@
+package freebox
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMACSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:01", true},
+		{"AA:BB:CC:DD:EE:01", "aa:bb:cc:dd:ee:01", true},
+		{"AA:BB:CC:DD:EE:01", "aa-bb-cc-dd-ee-01", true},
+		{"AA:BB:CC:DD:EE:01", "AA-BB-CC-DD-EE-01", true},
+		{"AA:BB:CC:DD:EE:01", "aabb.ccdd.ee01", true},
+		{"aa-bb-cc-dd-ee-01", "aabb.ccdd.ee01", true},
+		{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02", false},
+		{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE", false},
+		// Invalid values are never equal, not even to themselves, so a
+		// typo is not hidden.
+		{"nas", "nas", false},
+		{"", "", false},
+		{"AA:BB:CC:DD:EE:01", "", false},
+	}
+	for _, tt := range tests {
+		for _, pair := range [][2]string{{tt.a, tt.b}, {tt.b, tt.a}} {
+			got, diags := macOf(pair[0]).StringSemanticEquals(context.Background(), macOf(pair[1]))
+			if diags.HasError() {
+				t.Fatalf("StringSemanticEquals(%q, %q): %v", pair[0], pair[1], diags)
+			}
+			if got != tt.want {
+				t.Errorf("StringSemanticEquals(%q, %q) = %t, want %t", pair[0], pair[1], got, tt.want)
+			}
+		}
+	}
+}